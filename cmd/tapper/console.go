@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tapper/pkg/terraform"
+
+	"github.com/spf13/cobra"
+)
+
+// consoleCmd represents the console command
+var consoleCmd = &cobra.Command{
+	Use:   "console <profile>",
+	Short: "Open an interactive terraform console in a single profile's workspace",
+	Long: `Run terraform console in the workspace of a single profile, initialized with its backend config
+and var file, to evaluate expressions against its state. The workspace is removed when the console exits.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Groups are not expanded: the console runs in exactly one profile
+		profile, exists := terraform.GetProfile(cfg, args[0])
+		if !exists {
+			fmt.Printf("Error: profile '%s' not found\n", args[0])
+			os.Exit(1)
+		}
+
+		// Ctrl-C belongs to the console; tapper keeps running so it can clean up afterwards,
+		// so the usual interrupt handling of newExecutor is not installed
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+
+		executor := configuredExecutor()
+		result, execErr := executor.Console(profile)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+
+		if execErr != nil {
+			fmt.Printf("Error opening console: %v\n", execErr)
+			os.Exit(1)
+		}
+		if result.Error != nil {
+			fmt.Printf("Console of profile '%s' failed: %v\n", profile.Name, result.Error)
+			os.Exit(exitProfileFailure)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(consoleCmd)
+}
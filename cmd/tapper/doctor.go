@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"tapper/pkg/terraform"
+	"tapper/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorCheck represents a single environment check performed by the doctor command
+type doctorCheck struct {
+	Name     string
+	Required bool
+	Run      func() (string, error)
+	Hint     string
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment for common setup problems",
+	Long: `Run a series of self-checks to diagnose setup problems.
+Verifies that terraform, fzf and the aws CLI are available, that the workspace
+parent directory is writable, and that the current directory is a valid module.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := 0
+		for _, check := range doctorChecks() {
+			detail, err := check.Run()
+			if err != nil {
+				status := "WARN"
+				if check.Required {
+					status = "FAIL"
+					failed++
+				}
+				fmt.Printf("[%s] %s: %v\n", status, check.Name, err)
+				fmt.Printf("       Hint: %s\n", check.Hint)
+				continue
+			}
+			fmt.Printf("[ OK ] %s: %s\n", check.Name, detail)
+		}
+
+		if failed > 0 {
+			fmt.Printf("\n%d required check(s) failed\n", failed)
+			os.Exit(1)
+		}
+		fmt.Println("\nAll required checks passed")
+	},
+}
+
+// doctorChecks returns the list of checks run by the doctor command
+func doctorChecks() []doctorCheck {
+	return []doctorCheck{
+		{
+			Name:     "terraform",
+			Required: true,
+			Run:      checkTerraform,
+			Hint:     "install terraform (or the binary set with --binary/TAPPER_TF_BINARY) and make sure it is available in PATH",
+		},
+		{
+			Name: "fzf",
+			Run:  checkBinary("fzf"),
+			Hint: "install fzf for fuzzy profile selection (a numbered menu is used otherwise)",
+		},
+		{
+			Name: "aws",
+			Run:  checkBinary("aws"),
+			Hint: "install the aws CLI to enable automatic SSO token refresh",
+		},
+		{
+			Name:     "workspace directory",
+			Required: true,
+			Run:      checkWorkspaceParent,
+			Hint:     "tapper creates workspaces next to the module; ensure the parent directory is writable",
+		},
+		{
+			Name:     "module",
+			Required: true,
+			Run:      checkModule,
+			Hint:     "run tapper from a directory containing .tf files with backend/ and vars/ directories",
+		},
+		{
+			Name: "backend",
+			Run:  checkBackend,
+			Hint: "add a backend block to the terraform block; local state in profile workspaces is lost on cleanup",
+		},
+	}
+}
+
+// checkBinary returns a check verifying that the named binary is in PATH
+func checkBinary(name string) func() (string, error) {
+	return func() (string, error) {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return "", fmt.Errorf("%s not found in PATH", name)
+		}
+		return path, nil
+	}
+}
+
+// checkTerraform verifies the configured terraform binary is in PATH and reports its version
+func checkTerraform() (string, error) {
+	version, path, err := terraformVersion()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s)", version, path), nil
+}
+
+// checkWorkspaceParent verifies the workspace parent directory is writable
+func checkWorkspaceParent() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	parent := filepath.Dir(cwd)
+	if dir := workspaceParentDir(); dir != "" {
+		parent = dir
+	}
+	if err := utils.CheckDirWritable(parent); err != nil {
+		return "", fmt.Errorf("%s is not writable: %w", parent, err)
+	}
+	return parent, nil
+}
+
+// checkModule verifies the current directory looks like a tapper-managed module
+func checkModule() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	active, err := utils.HasActiveFiles(cwd)
+	if err != nil {
+		return "", fmt.Errorf("error reading module directory: %w", err)
+	}
+	if !active {
+		return "", fmt.Errorf("%s does not contain any active terraform files", cwd)
+	}
+
+	cfg, err := terraform.LoadConfig()
+	if err != nil {
+		return "", fmt.Errorf("error loading profiles: %w", err)
+	}
+	if len(cfg.Profiles) == 0 {
+		return "", fmt.Errorf("no profiles detected")
+	}
+	return fmt.Sprintf("%d profile(s) detected", len(cfg.Profiles)), nil
+}
+
+// checkBackend verifies the module configures a remote backend
+func checkBackend() (string, error) {
+	hasBackend, err := utils.HasBackendBlock(".")
+	if err != nil {
+		return "", err
+	}
+	if !hasBackend {
+		return "", fmt.Errorf("no backend configured, state would be local to each workspace")
+	}
+	return "backend configured", nil
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"tapper/pkg/terraform"
+	"tapper/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// fmtCmd represents the fmt command
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Run terraform fmt on the module",
+	Long: `Run terraform fmt in the module directory. Formatting does not depend on profiles,
+so no workspaces are created. With --check, files that would change are listed with a diff
+and tapper exits non-zero without modifying them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		utils.IsActiveDir()
+
+		check, _ := cmd.Flags().GetBool("check")
+		configureBinary()
+
+		fmtCommand := terraform.NewCommandBuilder().BuildFmtCommand(check)
+		fmtCommand.Stdout = os.Stdout
+		fmtCommand.Stderr = os.Stderr
+
+		err := fmtCommand.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if check {
+				fmt.Println("Formatting required for the files listed above.")
+			}
+			os.Exit(exitErr.ExitCode())
+		}
+		if err != nil {
+			fmt.Printf("Error running terraform fmt: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.Flags().Bool("check", false, "Report files that need formatting without modifying them")
+}
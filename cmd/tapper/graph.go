@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"tapper/pkg/terraform"
+	"tapper/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// graphPNG is the file the graph is rendered to with graphviz, if set
+var graphPNG string
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph <profile>",
+	Short: "Print the resource dependency graph of a single profile in DOT format",
+	Long: `Run terraform graph in the workspace of a single profile and print the DOT graph to stdout.
+With --png the graph is rendered to a file by graphviz instead; when graphviz is not installed,
+the DOT graph is printed as usual. Read-only: nothing is planned or applied.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Groups are not expanded: the graph is drawn for exactly one profile
+		profile, exists := terraform.GetProfile(cfg, args[0])
+		if !exists {
+			fmt.Printf("Error: profile '%s' not found\n", args[0])
+			os.Exit(1)
+		}
+
+		// Keep stdout clean for the DOT graph by sending progress to stderr
+		var graph bytes.Buffer
+		executor := newExecutor()
+		executor.SetProgressWriter(os.Stderr)
+		result, execErr := executor.Graph(profile, &graph)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+
+		if execErr != nil {
+			fmt.Fprintf(os.Stderr, "Error running graph: %v\n", execErr)
+			os.Exit(1)
+		}
+		if result.Error != nil {
+			fmt.Fprintf(os.Stderr, "Graph of profile '%s' failed: %v\n", profile.Name, result.Error)
+			os.Exit(exitProfileFailure)
+		}
+
+		if graphPNG != "" {
+			err := utils.RenderDOT(graph.Bytes(), "png", graphPNG)
+			if err == nil {
+				fmt.Fprintf(os.Stderr, "Graph of profile '%s' written to %s\n", profile.Name, graphPNG)
+				return
+			}
+			if !errors.Is(err, utils.ErrNoGraphviz) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Note: %v, printing the DOT graph instead\n", err)
+		}
+		os.Stdout.Write(graph.Bytes())
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphPNG, "png", "", "Render the graph to this PNG file with graphviz (dot)")
+	rootCmd.AddCommand(graphCmd)
+}
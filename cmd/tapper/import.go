@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"tapper/pkg/terraform"
+
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <profile> <address> <id>",
+	Short: "Import an existing resource into a single profile's state",
+	Long: `Run terraform import in the workspace of a single profile, using its backend config and var file.
+The address is the resource address in the configuration and id the provider-specific resource ID.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		profileName, address, id := args[0], args[1], args[2]
+		if strings.TrimSpace(address) == "" || strings.TrimSpace(id) == "" {
+			fmt.Println("Error: import requires a non-empty resource address and id")
+			os.Exit(1)
+		}
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Groups are not expanded: import targets exactly one profile
+		profile, exists := terraform.GetProfile(cfg, profileName)
+		if !exists {
+			fmt.Printf("Error: profile '%s' not found\n", profileName)
+			os.Exit(1)
+		}
+
+		executor := newExecutor()
+
+		// Shared var files and overrides apply as for plan and apply, after the profile's var file
+		varFiles, _ := cmd.Flags().GetStringArray("var-file")
+		if err := executor.SetVarFiles(varFiles); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		vars, _ := cmd.Flags().GetStringArray("var")
+		if err := executor.SetVars(vars); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		result, execErr := executor.Import(profile, address, id)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+
+		if execErr != nil {
+			fmt.Printf("Error importing resource: %v\n", execErr)
+			os.Exit(1)
+		}
+		if result.Error != nil {
+			fmt.Printf("Import of %s into profile '%s' failed: %v\n", address, profileName, result.Error)
+			os.Exit(exitProfileFailure)
+		}
+		fmt.Printf("Imported %s into profile '%s' (%v)\n", address, profileName, result.Duration)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringArray("var-file", nil, "Add a shared var file after the profile's own, overriding its values (repeatable)")
+	importCmd.Flags().StringArray("var", nil, "Override a variable as key=value, taking precedence over the profile's var file (repeatable)")
+}
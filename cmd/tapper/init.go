@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"tapper/pkg/terraform"
+
+	"github.com/spf13/cobra"
+)
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:     "init [profile...]",
+	Aliases: []string{"i"},
+	Short:   "Run only terraform init with selected profile(s)",
+	Long: `Run terraform init in an isolated workspace for one or more profiles and stop.
+Useful to warm provider caches and validate backend configurations without planning.
+If no profile is specified, displays an interactive selection menu.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+
+		profiles, err := resolveProfiles(cfg, args)
+		if err != nil {
+			fmt.Printf("Error selecting profiles: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No profiles selected.")
+			return
+		}
+
+		executor := newExecutor()
+
+		executor.Reinit, _ = cmd.Flags().GetBool("reinit")
+		initArgs, _ := cmd.Flags().GetStringArray("init-arg")
+		if err := executor.SetInitArgs(initArgs); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+
+		results, err := executor.InitProfiles(profiles)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+		if err != nil {
+			fmt.Printf("Error initializing profiles: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+
+		failed := 0
+		fmt.Println("\nInit summary:")
+		for _, result := range results {
+			if result.Success {
+				fmt.Printf("  ✅ %s (%v)\n", result.ProfileName, result.Duration)
+			} else {
+				failed++
+				fmt.Printf("  ❌ %s: %v\n", result.ProfileName, result.Error)
+			}
+		}
+
+		if failed > 0 {
+			os.Exit(exitProfileFailure)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().Bool("reinit", false, "Always run terraform init, even when it looks up to date")
+	initCmd.Flags().StringArray("init-arg", nil, "Extra argument for terraform init in every profile, e.g. -upgrade; forces init to run (repeatable)")
+}
@@ -6,6 +6,11 @@ import (
 )
 
 func Execute() {
+	// Unknown subcommands are delegated to tapper-<subcommand> plugins on PATH
+	if path, ok := findPlugin(os.Args[1:]); ok {
+		os.Exit(runPlugin(path, os.Args[2:]))
+	}
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
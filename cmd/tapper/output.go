@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"tapper/pkg/terraform"
+
+	"github.com/spf13/cobra"
+)
+
+// terraformOutput represents a single value from terraform output -json
+type terraformOutput struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// outputCmd represents the output command
+var outputCmd = &cobra.Command{
+	Use:     "output [profile...]",
+	Aliases: []string{"o", "out"},
+	Short:   "Show terraform outputs for selected profile(s)",
+	Long: `Run terraform output in each selected profile's workspace and print a merged view keyed by profile name.
+If no profile is specified, displays an interactive selection menu.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+
+		profiles, err := resolveProfiles(cfg, args)
+		if err != nil {
+			fmt.Printf("Error selecting profiles: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No profiles selected.")
+			return
+		}
+
+		executor := newExecutor()
+
+		// Keep stdout clean for the JSON document by sending progress to stderr
+		if asJSON {
+			executor.SetProgressWriter(os.Stderr)
+		}
+		results, execErr := executor.Output(profiles)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+
+		outputs := make(map[string]map[string]terraformOutput)
+		for _, result := range results {
+			if !result.Success {
+				continue
+			}
+			profileOutputs, err := parseTerraformOutputs(result.Stdout)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing outputs for profile %s: %v\n", result.ProfileName, err)
+				execErr = err
+				continue
+			}
+			outputs[result.ProfileName] = profileOutputs
+		}
+
+		if asJSON {
+			data, err := json.MarshalIndent(outputs, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding outputs: %v\n", err)
+				os.Exit(exitSetupError)
+			}
+			fmt.Println(string(data))
+		} else {
+			printOutputs(outputs)
+		}
+
+		if execErr != nil {
+			fmt.Fprintf(os.Stderr, "Error reading outputs:\n%v\n", execErr)
+			if results == nil {
+				os.Exit(exitSetupError)
+			}
+			os.Exit(exitProfileFailure)
+		}
+	},
+}
+
+// parseTerraformOutputs decodes terraform output -json, treating empty output as no outputs
+func parseTerraformOutputs(stdout string) (map[string]terraformOutput, error) {
+	outputs := make(map[string]terraformOutput)
+	if strings.TrimSpace(stdout) == "" {
+		return outputs, nil
+	}
+	if err := json.Unmarshal([]byte(stdout), &outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// printOutputs prints the outputs of every profile in a human readable form
+func printOutputs(outputs map[string]map[string]terraformOutput) {
+	profileNames := make([]string, 0, len(outputs))
+	for name := range outputs {
+		profileNames = append(profileNames, name)
+	}
+	sort.Strings(profileNames)
+
+	for _, profileName := range profileNames {
+		fmt.Printf("=== Profile: %s ===\n", profileName)
+
+		profileOutputs := outputs[profileName]
+		if len(profileOutputs) == 0 {
+			fmt.Println("{}")
+			continue
+		}
+
+		names := make([]string, 0, len(profileOutputs))
+		for name := range profileOutputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			output := profileOutputs[name]
+			value := string(output.Value)
+			if output.Sensitive {
+				value = "(sensitive value)"
+			}
+			fmt.Printf("%s = %s\n", name, value)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(outputCmd)
+
+	outputCmd.Flags().Bool("json", false, "Print all outputs as a single JSON document keyed by profile")
+}
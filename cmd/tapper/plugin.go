@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"tapper/pkg/terraform"
+)
+
+// pluginPrefix is the executable name prefix used to discover plugins on PATH
+const pluginPrefix = "tapper-"
+
+// findPlugin returns the plugin executable for args when the first argument is not a built-in command
+func findPlugin(args []string) (string, bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", false
+	}
+
+	if _, _, err := rootCmd.Find(args); err == nil {
+		return "", false
+	}
+
+	path, err := exec.LookPath(pluginPrefix + args[0])
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// runPlugin executes a plugin with the remaining args and the profile context in its environment.
+// Plugins receive the following environment variables:
+//
+//	TAPPER_MODULE_DIR        absolute path of the current module directory
+//	TAPPER_WORKSPACE_PARENT  directory in which tapper creates per-profile workspaces
+//	TAPPER_PROFILES          comma-separated names of the detected profiles
+//	TAPPER_PROFILES_JSON     JSON array of the detected profiles, as in 'tapper profile list'
+func runPlugin(path string, args []string) int {
+	env, err := pluginEnv()
+	if err != nil {
+		fmt.Printf("Error preparing plugin environment: %v\n", err)
+		return 1
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), env...)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Printf("Error running plugin %s: %v\n", filepath.Base(path), err)
+		return 1
+	}
+	return 0
+}
+
+// pluginEnv builds the environment variables describing the profile context
+func pluginEnv() ([]string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	// Plugins may run outside a module, so profile detection errors are not fatal
+	profiles := []terraform.Profile{}
+	if cfg, err := terraform.LoadConfig(); err == nil {
+		for _, profile := range cfg.Profiles {
+			profiles = append(profiles, profile.Redacted())
+		}
+	}
+
+	profilesJSON, err := json.Marshal(profiles)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding profiles: %w", err)
+	}
+
+	workspaceParent := filepath.Dir(cwd)
+	if dir := workspaceParentDir(); dir != "" {
+		workspaceParent = dir
+	}
+
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		names[i] = profile.Name
+	}
+
+	return []string{
+		"TAPPER_MODULE_DIR=" + cwd,
+		"TAPPER_WORKSPACE_PARENT=" + workspaceParent,
+		"TAPPER_PROFILES=" + strings.Join(names, ","),
+		"TAPPER_PROFILES_JSON=" + string(profilesJSON),
+	}, nil
+}
@@ -1,11 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"tapper/pkg/terraform"
-	"tapper/pkg/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -33,7 +33,7 @@ var createProfileCmd = &cobra.Command{
 	Short:   "Create a new profile",
 	Long:    `Create a new Terraform profile with the specified backend config and var file.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		utils.IsActiveDir()
+		requireModuleDir()
 
 		fmt.Println("Note: Profiles are now auto-detected from filesystem.")
 		fmt.Println("To create a profile, simply add matching .tfbackend and .tfvars files")
@@ -57,11 +57,11 @@ var createProfileCmd = &cobra.Command{
 
 		// Set default directories if not provided
 		if backendDir == "" {
-			backendDir = "backend"
+			backendDir = terraform.DefaultBackendDir
 		}
 
 		if varsDir == "" {
-			varsDir = "vars"
+			varsDir = terraform.DefaultVarsDir
 		}
 
 		fmt.Printf("To create profile '%s', ensure these files exist:\n", profileName)
@@ -84,6 +84,21 @@ var listProfilesCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			// Scripts expect an array, also when no profiles are found
+			profiles := make([]terraform.Profile, len(cfg.Profiles))
+			for i, profile := range cfg.Profiles {
+				profiles[i] = profile.Redacted()
+			}
+			data, err := json.MarshalIndent(profiles, "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding profiles: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
 		if len(cfg.Profiles) == 0 {
 			fmt.Println("No profiles found")
 			fmt.Println("Make sure you have matching .tfbackend and .tfvars files in backend/ and vars/ directories")
@@ -101,6 +116,30 @@ var listProfilesCmd = &cobra.Command{
 	},
 }
 
+// doctorProfilesCmd reports backend and var files that do not form a profile
+var doctorProfilesCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Explain why backend or var files are not detected as profiles",
+	Long:  `List every backend and var file that does not pair up into a profile, with the reason. Read-only.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		unmatched, err := terraform.FindUnmatchedFiles()
+		if err != nil {
+			fmt.Printf("Error checking profile files: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(unmatched) == 0 {
+			fmt.Println("All backend and var files pair up into profiles")
+			return
+		}
+
+		fmt.Println("Files not detected as profiles:")
+		for _, file := range unmatched {
+			fmt.Printf("- %s: %s\n", file.Path, file.Reason)
+		}
+	},
+}
+
 // deleteProfileCmd deletes a profile
 var deleteProfileCmd = &cobra.Command{
 	Use:     "delete",
@@ -126,19 +165,21 @@ var deleteProfileCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(profileCmd)
-	profileCmd.AddCommand(createProfileCmd, listProfilesCmd, deleteProfileCmd)
+	profileCmd.AddCommand(createProfileCmd, listProfilesCmd, doctorProfilesCmd, deleteProfileCmd)
 
 	// Add flags for the create command
 	createProfileCmd.Flags().StringVarP(&profileName, "name", "n", "", "Profile name (required)")
 	createProfileCmd.Flags().StringVarP(&backendConfig, "backend-config", "b", "", "Backend config file (required)")
 	createProfileCmd.Flags().StringVarP(&varFile, "var-file", "v", "", "Var file (required)")
-	createProfileCmd.Flags().StringVarP(&backendDir, "backend-dir", "", "backend", "Backend directory")
-	createProfileCmd.Flags().StringVarP(&varsDir, "vars-dir", "", "vars", "Variables directory")
+	createProfileCmd.Flags().StringVarP(&backendDir, "backend-dir", "", terraform.DefaultBackendDir, "Backend directory")
+	createProfileCmd.Flags().StringVarP(&varsDir, "vars-dir", "", terraform.DefaultVarsDir, "Variables directory")
 
 	createProfileCmd.MarkFlagRequired("name")
 	createProfileCmd.MarkFlagRequired("backend-config")
 	createProfileCmd.MarkFlagRequired("var-file")
 
+	listProfilesCmd.Flags().Bool("json", false, "Print the profiles as a JSON array")
+
 	// Add flags for the delete command
 	deleteProfileCmd.Flags().StringVarP(&profileName, "name", "n", "", "Profile name (required)")
 	deleteProfileCmd.MarkFlagRequired("name")
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tapper/pkg/terraform"
+	"tapper/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// providersCmd represents the providers command
+var providersCmd = &cobra.Command{
+	Use:   "providers [profile...]",
+	Short: "Report provider versions resolved for selected profile(s)",
+	Long: `Initialize each selected profile's workspace and report the provider versions recorded
+in its .terraform.lock.hcl. Profiles of one module share its lock file, so versions are reported per
+module; with --recursive, providers locked to different versions across modules are flagged.
+If no profile is specified, displays an interactive selection menu.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		profiles, err := resolveProfiles(cfg, args)
+		if err != nil {
+			fmt.Printf("Error selecting profiles: %v\n", err)
+			os.Exit(1)
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No profiles selected.")
+			return
+		}
+
+		moduleOf := make(map[string]string, len(profiles))
+		for _, profile := range profiles {
+			moduleOf[profile.Name] = moduleLabel(profile.ModuleDir)
+		}
+
+		executor := newExecutor()
+		results, err := executor.InitProfiles(profiles)
+
+		// Lock files must be read before the workspaces are removed
+		versions := make(map[string]map[string]string)
+		users := make(map[string][]string)
+		failed := false
+		for _, result := range results {
+			if !result.Success {
+				failed = true
+				fmt.Printf("Error initializing profile %s: %v\n", result.ProfileName, result.Error)
+				continue
+			}
+			module := moduleOf[result.ProfileName]
+			users[module] = append(users[module], result.ProfileName)
+			if _, read := versions[module]; read {
+				continue
+			}
+			lockVersions, lockErr := utils.ParseProviderLock(filepath.Join(result.WorkingDir, ".terraform.lock.hcl"))
+			if lockErr != nil {
+				failed = true
+				fmt.Printf("Error reading providers for profile %s: %v\n", result.ProfileName, lockErr)
+				continue
+			}
+			versions[module] = lockVersions
+		}
+
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+		if err != nil {
+			fmt.Printf("Error initializing profiles: %v\n", err)
+			os.Exit(1)
+		}
+
+		if printProviderVersions(versions, users) || failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// moduleLabel names a profile's module directory, "." for the root module
+func moduleLabel(moduleDir string) string {
+	if moduleDir == "" {
+		return "."
+	}
+	return moduleDir
+}
+
+// printProviderVersions prints a provider-by-module version table, naming the profiles sharing each
+// module's lock file, and reports whether any versions differ between modules
+func printProviderVersions(versions map[string]map[string]string, users map[string][]string) bool {
+	var modules []string
+	providerSet := make(map[string]bool)
+	for module, lockVersions := range versions {
+		modules = append(modules, module)
+		for provider := range lockVersions {
+			providerSet[provider] = true
+		}
+	}
+	sort.Strings(modules)
+
+	var providers []string
+	for provider := range providerSet {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	if len(providers) == 0 {
+		fmt.Println("No providers locked for the selected profiles")
+		return false
+	}
+
+	fmt.Println("\nLock files:")
+	for _, module := range modules {
+		profileNames := append([]string{}, users[module]...)
+		sort.Strings(profileNames)
+		fmt.Printf("   %s: %s\n", module, strings.Join(profileNames, ", "))
+	}
+
+	mismatch := false
+	fmt.Println("\nProvider versions:")
+	for _, provider := range providers {
+		distinct := make(map[string]bool)
+		var entries []string
+		for _, module := range modules {
+			version, exists := versions[module][provider]
+			if !exists {
+				version = "-"
+			}
+			distinct[version] = true
+			entries = append(entries, fmt.Sprintf("%s=%s", module, version))
+		}
+
+		marker := "  "
+		if len(distinct) > 1 {
+			marker = "⚠️ "
+			mismatch = true
+		}
+		fmt.Printf("%s %s: %s\n", marker, provider, strings.Join(entries, ", "))
+	}
+
+	if mismatch {
+		fmt.Println("\nWarning: some providers are locked to different versions across modules")
+	}
+	return mismatch
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+}
@@ -2,14 +2,48 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"tapper/pkg/terraform"
 	"tapper/pkg/utils"
+	"tapper/pkg/workspace"
 
 	"github.com/spf13/cobra"
 )
 
+// Exit codes let CI tell a broken setup apart from profiles whose terraform run failed
+const (
+	exitSetupError     = 1
+	exitProfileFailure = 2
+)
+
+var (
+	// workspaceDir overrides the directory in which profile workspaces are created
+	workspaceDir string
+	// workspaceMode selects whether profile workspaces symlink or copy the module
+	workspaceMode string
+	// keepWorkspaces leaves the profile workspaces in place for debugging
+	keepWorkspaces bool
+	// binary is the terraform-compatible executable, e.g. tofu
+	binary string
+	// isolateDirs are module directories mirrored per file in each workspace, on top of tapper.json
+	isolateDirs []string
+	// excludeProfiles are left out of the selected profiles, or of all profiles when none are named
+	excludeProfiles []string
+	// maxConcurrency limits how many profiles execute at the same time
+	maxConcurrency int
+	// noColor disables ANSI colors in all output
+	noColor bool
+	// colorPalette is the palette profile names are colored with
+	colorPalette string
+	// profileFilter is a regular expression narrowing the profiles offered by the interactive selector
+	profileFilter string
+	// workingDir is the module directory tapper runs in instead of the current directory
+	workingDir string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "tapper",
 	Short: "Tapper - A Terraform profile manager",
@@ -18,6 +52,15 @@ with different backend configurations and variable files.
 
 It automatically detects profiles from matching .tfbackend and .tfvars files
 in backend/ and vars/ directories.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if workingDir == "" {
+			return
+		}
+		if err := changeWorkingDir(workingDir); err != nil {
+			fmt.Printf("Error changing to working directory: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	},
 }
 
 // applyCmd represents the apply command
@@ -62,100 +105,607 @@ If multiple profiles are specified, runs in parallel across all profiles.`,
 	},
 }
 
+// refreshCmd represents the refresh command
+var refreshCmd = &cobra.Command{
+	Use:   "refresh [profile...]",
+	Short: "Update state to match real infrastructure for selected profile(s)",
+	Long: `Run terraform apply -refresh-only with one or more profiles.
+The drift found by terraform plan -refresh-only is shown for review first, and only approved
+profiles record it in their state. No infrastructure is changed.
+If no profile is specified, displays an interactive selection menu.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		executeCommand("refresh", args, cmd)
+	},
+}
+
 // executeCommand handles the execution logic for all terraform commands
 func executeCommand(command string, profileArgs []string, cmd *cobra.Command) {
-	utils.IsActiveDir()
+	requireModuleDir()
 
 	cfg, err := terraform.LoadConfig()
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitSetupError)
 	}
 
-	var profileNames []string
-	if len(profileArgs) == 0 {
-		// No profiles specified, let user select
-		var err error
-		profileNames, err = selectMultipleProfiles(cfg)
+	resume, _ := cmd.Flags().GetBool("resume")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if resume && dryRun {
+		fmt.Println("Error: --resume and --dry-run cannot be combined")
+		os.Exit(exitSetupError)
+	}
+
+	// Markdown output renders the plans for a PR comment instead of reviewing them
+	outputFormat, _ := cmd.Flags().GetString("output")
+	switch outputFormat {
+	case "text":
+	case "markdown":
+		if resume {
+			fmt.Println("Error: --resume and --output markdown cannot be combined")
+			os.Exit(exitSetupError)
+		}
+		dryRun = true
+	default:
+		fmt.Printf("Error: invalid --output %q, expected text or markdown\n", outputFormat)
+		os.Exit(exitSetupError)
+	}
+	markdown := outputFormat == "markdown"
+
+	// Keep stdout clean for the markdown document by sending progress to stderr
+	var progress io.Writer = os.Stdout
+	if markdown {
+		progress = os.Stderr
+	}
+
+	// A saved plan is either written for later (--save-plan) or applied as reviewed (--from-plan)
+	planOnly, _ := cmd.Flags().GetBool("plan-only")
+	savePlan, _ := cmd.Flags().GetString("save-plan")
+	fromPlan, _ := cmd.Flags().GetString("from-plan")
+	if fromPlan != "" && (resume || dryRun || planOnly || savePlan != "" || len(profileArgs) > 0) {
+		fmt.Println("Error: --from-plan applies the saved profiles and cannot be combined with profile arguments, --resume, --dry-run, --plan-only, --save-plan or --output markdown")
+		os.Exit(exitSetupError)
+	}
+	if savePlan != "" && (resume || dryRun || planOnly) {
+		fmt.Println("Error: --save-plan cannot be combined with --resume, --dry-run, --plan-only or --output markdown")
+		os.Exit(exitSetupError)
+	}
+
+	// --all skips the selector; --exclude alone already starts from all profiles
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		if len(profileArgs) > 0 || len(excludeProfiles) > 0 || resume || fromPlan != "" {
+			fmt.Println("Error: --all cannot be combined with profile arguments, --exclude (which already starts from all profiles), --resume or --from-plan")
+			os.Exit(exitSetupError)
+		}
+		profileArgs = terraform.ListProfiles(cfg)
+	}
+
+	var profiles []terraform.Profile
+	if !resume && fromPlan == "" {
+		profiles, err = resolveProfiles(cfg, profileArgs)
 		if err != nil {
 			fmt.Printf("Error selecting profiles: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitSetupError)
 		}
-		if len(profileNames) == 0 {
+		if len(profiles) == 0 {
 			fmt.Println("No profiles selected.")
 			return
 		}
-	} else {
-		profileNames = profileArgs
+		fmt.Fprintf(progress, "Resolved %d profile(s): %s\n", len(profiles), strings.Join(profileNames(profiles), ", "))
 	}
 
-	var profiles []terraform.Profile
-	for _, profileName := range profileNames {
-		profile, exists := terraform.GetProfile(cfg, profileName)
-		if !exists {
-			fmt.Printf("Profile '%s' not found\n", profileName)
-			os.Exit(1)
-		}
-		profiles = append(profiles, profile)
+	executor := newExecutor()
+	if markdown {
+		executor.SetColor(false)
+		executor.SetProgressWriter(progress)
 	}
-	fmt.Printf("Selected profiles: %v\n", profiles)
 
-	executor, err := terraform.NewExecutor()
+	// Explicit flags take precedence over profile defaults from the manifest
+	var lock *bool
+	if lockValue, err := cmd.Flags().GetBool("lock"); err == nil && cmd.Flags().Changed("lock") {
+		lock = &lockValue
+	}
+	lockTimeout, _ := cmd.Flags().GetDuration("lock-timeout")
+	additionalArgs, err := terraform.LockArgs(lock, lockTimeout)
 	if err != nil {
-		fmt.Printf("Error creating executor: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitSetupError)
 	}
-
-	var additionalArgs []string
-	lockValue, err := cmd.Flags().GetBool("lock")
-	if err == nil {
-		if lockValue {
-			additionalArgs = append(additionalArgs, "-lock=true")
-		} else {
-			additionalArgs = append(additionalArgs, "-lock=false")
-		}
+	parallelism, err := cmd.Flags().GetInt("terraform-parallelism")
+	if err == nil && cmd.Flags().Changed("terraform-parallelism") {
+		additionalArgs = append(additionalArgs, fmt.Sprintf("-parallelism=%d", parallelism))
 	}
 
 	// Set additional args on the executor
 	if err := executor.SetAdditionalArgs(additionalArgs); err != nil {
 		fmt.Printf("Error setting additional arguments: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitSetupError)
+	}
+
+	// Targets apply to every selected profile
+	if targets, err := cmd.Flags().GetStringArray("target"); err == nil {
+		if err := executor.SetTargets(targets); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	}
+
+	// Shared var files and variable overrides apply to every selected profile, after its var file
+	if varFiles, err := cmd.Flags().GetStringArray("var-file"); err == nil {
+		if err := executor.SetVarFiles(varFiles); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	}
+	if vars, err := cmd.Flags().GetStringArray("var"); err == nil {
+		if err := executor.SetVars(vars); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
 	}
 
-	fmt.Printf("Creating execution plan for %s across %d profile(s)...\n", command, len(profiles))
-	//TODO: Add target selection
-	plan, err := executor.PlanExecution(command, profiles)
+	// Configure output buffering and logging
+	if bufferMB, err := cmd.Flags().GetInt("output-buffer-mb"); err == nil {
+		executor.OutputBufferSize = bufferMB * 1024 * 1024
+	}
+	if logDir, err := cmd.Flags().GetString("log-dir"); err == nil {
+		executor.LogDir = logDir
+	}
+	if jsonPlanDir, err := cmd.Flags().GetString("json-plan"); err == nil {
+		executor.JSONPlanDir = jsonPlanDir
+	}
+	if quiet, err := cmd.Flags().GetBool("quiet"); err == nil {
+		executor.SetQuiet(quiet)
+	}
+	if progress, err := cmd.Flags().GetBool("progress"); err == nil {
+		executor.SetProgress(progress)
+	}
+	if logFile, err := cmd.Flags().GetString("log-file"); err == nil {
+		executor.SetLogFile(logFile)
+	}
+	if validateVars, err := cmd.Flags().GetBool("validate-vars"); err == nil {
+		executor.ValidateVars = validateVars
+	}
+	executor.DryRun = dryRun
+	if format, err := cmd.Flags().GetString("timestamp-format"); err == nil {
+		if noTimestamps, _ := cmd.Flags().GetBool("no-timestamps"); noTimestamps {
+			format = ""
+		}
+		executor.SetTimestampFormat(format)
+	}
+	if heartbeat, err := cmd.Flags().GetDuration("heartbeat"); err == nil {
+		executor.SetHeartbeat(heartbeat)
+	}
+	if grouped, err := cmd.Flags().GetBool("grouped"); err == nil {
+		executor.GroupedOutput = grouped
+	}
+	if pager, err := cmd.Flags().GetBool("pager"); err == nil {
+		executor.SetPager(pager)
+	}
+	if toggle, err := cmd.Flags().GetBool("toggle-output"); err == nil {
+		executor.SetInteractiveOutput(toggle)
+	}
+	if reinit, err := cmd.Flags().GetBool("reinit"); err == nil {
+		executor.Reinit = reinit
+	}
+	if initArgs, err := cmd.Flags().GetStringArray("init-arg"); err == nil {
+		if err := executor.SetInitArgs(initArgs); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	}
+	if reason, err := cmd.Flags().GetString("reason"); err == nil {
+		executor.Reason = reason
+	}
+	if autoApprove, err := cmd.Flags().GetBool("auto-approve"); err == nil {
+		executor.SetAutoApprove(autoApprove)
+	}
+	if workspaceMap, err := cmd.Flags().GetString("workspace-map"); err == nil {
+		executor.WorkspaceMapFile = workspaceMap
+	}
+	if pageSize, err := cmd.Flags().GetInt("review-page-size"); err == nil {
+		if err := executor.SetReviewPageSize(pageSize); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	}
+	if authRetries, err := cmd.Flags().GetInt("auth-retries"); err == nil {
+		if authRetries < 0 {
+			fmt.Println("Error: --auth-retries must not be negative")
+			os.Exit(exitSetupError)
+		}
+		executor.AuthRetries = authRetries
+	}
+	if timeout, err := cmd.Flags().GetDuration("timeout"); err == nil {
+		executor.Timeout = timeout
+	}
+	if summaryOnly, err := cmd.Flags().GetBool("summary-only"); err == nil {
+		executor.SummaryOnly = summaryOnly
+	}
+	if ticket, err := cmd.Flags().GetString("ticket"); err == nil {
+		executor.Ticket = ticket
+	}
+	if policy, err := cmd.Flags().GetString("on-no-changes"); err == nil {
+		if applyUnchanged, _ := cmd.Flags().GetBool("apply-unchanged"); applyUnchanged {
+			if cmd.Flags().Changed("on-no-changes") && policy != terraform.NoChangesApply {
+				fmt.Println("Error: --apply-unchanged cannot be combined with --on-no-changes " + policy)
+				os.Exit(exitSetupError)
+			}
+			policy = terraform.NoChangesApply
+		}
+		if err := executor.SetNoChangesPolicy(policy); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	}
+
+	if mode, err := cmd.Flags().GetString("concurrency-group"); err == nil {
+		if err := executor.SetConcurrencyGroups(mode); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	}
+
+	if force, err := cmd.Flags().GetBool("force"); err == nil {
+		executor.AllowSharedState = force
+	}
+
+	// Passthrough runs a single profile directly and exits with terraform's code
+	if passthrough, _ := cmd.Flags().GetBool("passthrough"); passthrough {
+		if resume || dryRun || planOnly || savePlan != "" || fromPlan != "" || len(profiles) != 1 {
+			fmt.Println("Error: --passthrough requires exactly one profile and cannot be combined with --resume, --dry-run, --plan-only, --save-plan or --from-plan")
+			os.Exit(exitSetupError)
+		}
+		result, err := executor.Passthrough(command, profiles[0])
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+		if err != nil {
+			fmt.Printf("Error executing %s: %v\n", command, err)
+			os.Exit(exitSetupError)
+		}
+		recordUsage([]terraform.ExecutionResult{result})
+		os.Exit(result.ProcessExitCode())
+	}
+
+	var plan *terraform.ExecutionPlan
+	switch {
+	case resume:
+		fmt.Fprintf(progress, "Resuming saved execution plan for %s...\n", command)
+		plan, err = executor.ResumeExecution(command)
+	case fromPlan != "":
+		fmt.Fprintf(progress, "Loading reviewed plan for %s from %s...\n", command, fromPlan)
+		plan, err = executor.ApplySavedPlan(fromPlan, command, cfg)
+	default:
+		fmt.Fprintf(progress, "Creating execution plan for %s across %d profile(s)...\n", command, len(profiles))
+		plan, err = executor.PlanExecution(command, profiles)
+	}
 	if err != nil {
 		fmt.Printf("Error creating execution plan: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitSetupError)
 	}
 
-	defer func() {
-		if err := executor.WorkspaceCleanup(plan); err != nil {
-			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", err)
+	// Plan files loaded with --from-plan belong to the user and are left in place
+	cleanupPlan := plan
+	if fromPlan != "" {
+		cleanupPlan = nil
+	}
+	cleanup := func() {
+		if err := executor.WorkspaceCleanup(cleanupPlan); err != nil {
+			fmt.Fprintf(progress, "Warning: Error cleaning up workspaces: %v\n", err)
 		}
-	}()
+	}
+	defer cleanup()
+
+	if markdown {
+		if err := terraform.RenderMarkdown(os.Stdout, plan); err != nil {
+			fmt.Fprintf(os.Stderr, "Error rendering markdown: %v\n", err)
+			cleanup()
+			os.Exit(exitSetupError)
+		}
+		if terraform.HasFailures(plan.Results) {
+			cleanup()
+			os.Exit(exitProfileFailure)
+		}
+		return
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run complete: %s was not executed.\n", command)
+		return
+	}
+
+	// Saved plans are applied later with --from-plan, possibly by someone else
+	if savePlan != "" {
+		removePlanState()
+		if err := terraform.SaveApprovedPlan(plan, savePlan); err != nil {
+			fmt.Printf("Error saving plan: %v\n", err)
+			cleanup()
+			os.Exit(exitSetupError)
+		}
+		terraform.PrintPlanOnly(plan)
+		fmt.Printf("Saved reviewed plan to %s; apply it with: tapper %s --from-plan %s\n", savePlan, command, savePlan)
+		if terraform.HasFailures(plan.Results) {
+			cleanup()
+			os.Exit(exitProfileFailure)
+		}
+		return
+	}
+
+	// Plan-only runs keep the review but never execute the approved profiles
+	if planOnly {
+		removePlanState()
+		terraform.PrintPlanOnly(plan)
+		if terraform.HasFailures(plan.Results) {
+			cleanup()
+			os.Exit(exitProfileFailure)
+		}
+		return
+	}
 
 	if len(plan.ApprovedProfiles) == 0 {
+		removePlanState()
 		fmt.Println("No profiles approved or execution cancelled.")
+		if terraform.HasFailures(plan.Results) {
+			terraform.PrintSummary(plan, nil, colorEnabled())
+			cleanup()
+			os.Exit(exitProfileFailure)
+		}
 		return
 	}
 
 	// Execute the approved plan
 	fmt.Printf("Executing %s for approved profile(s)...\n", command)
-	//TODO: Show errors on failed execution
-	_, err = executor.ExecutePlan(plan)
+	results, err := executor.ExecutePlan(plan)
+	removePlanState()
+	recordUsage(results)
+	terraform.PrintSummary(plan, results, colorEnabled())
 	if err != nil {
-		fmt.Printf("Error executing plan: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("Error executing plan:\n%v\n", err)
+	}
+
+	// os.Exit skips deferred calls, so clean up before signaling the failure to CI.
+	// Every profile has run to completion at this point.
+	switch {
+	case err != nil && results == nil:
+		cleanup()
+		os.Exit(exitSetupError)
+	case terraform.HasFailures(results) || terraform.HasFailures(plan.Results):
+		cleanup()
+		os.Exit(exitProfileFailure)
+	}
+}
+
+// terraformBinary returns the executable from --binary or TAPPER_TF_BINARY, defaulting to terraform
+func terraformBinary() string {
+	if binary != "" {
+		return binary
+	}
+	if envBinary := os.Getenv("TAPPER_TF_BINARY"); envBinary != "" {
+		return envBinary
+	}
+	return terraform.DefaultBinary
+}
+
+// configureBinary selects the terraform executable, exiting when it is not installed
+func configureBinary() {
+	if err := terraform.SetBinary(terraformBinary()); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitSetupError)
+	}
+}
+
+// newExecutor creates an executor configured from the global flags that cleans up on Ctrl-C,
+// exiting on failure
+func newExecutor() *terraform.Executor {
+	executor := configuredExecutor()
+	handleInterrupts(executor)
+	return executor
+}
+
+// configuredExecutor creates an executor configured from the global flags, exiting on failure.
+// Interrupts are left to the caller, e.g. an interactive terraform that handles Ctrl-C itself.
+func configuredExecutor() *terraform.Executor {
+	configureBinary()
+
+	executor, err := terraform.NewExecutor()
+	if err != nil {
+		fmt.Printf("Error creating executor: %v\n", err)
+		os.Exit(exitSetupError)
+	}
+
+	executor.SetColor(colorEnabled())
+	if err := executor.SetColorPalette(colorPalette); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitSetupError)
+	}
+
+	if err := executor.SetMaxConcurrency(maxConcurrency); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitSetupError)
+	}
+
+	executor.KeepWorkspaces = keepWorkspaces
+	if err := executor.SetWorkspaceMode(workspaceMode); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitSetupError)
+	}
+
+	manifest, err := terraform.LoadManifest(terraform.ManifestFile)
+	if err != nil {
+		fmt.Printf("Error loading %s: %v\n", terraform.ManifestFile, err)
+		os.Exit(exitSetupError)
+	}
+	executor.SetIsolatedDirs(append(manifest.IsolateDirs, isolateDirs...))
+	if err := executor.AddWorkspaceIgnore(manifest.WorkspaceIgnore); err != nil {
+		fmt.Printf("Error loading %s: %v\n", terraform.ManifestFile, err)
+		os.Exit(exitSetupError)
+	}
+
+	if dir := workspaceParentDir(); dir != "" {
+		if err := executor.SetWorkspaceParent(dir); err != nil {
+			fmt.Printf("Error configuring workspace directory: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+	}
+	return executor
+}
+
+// colorEnabled reports whether output should be colored, honoring --no-color, NO_COLOR and non-TTY stdout
+func colorEnabled() bool {
+	return !noColor && utils.ColorSupported()
+}
+
+// workspaceParentDir returns the workspace directory override from --workspace-dir or TAPPER_WORKSPACE_DIR
+func workspaceParentDir() string {
+	if workspaceDir != "" {
+		return workspaceDir
+	}
+	return os.Getenv("TAPPER_WORKSPACE_DIR")
+}
+
+// resolveProfiles returns the profiles (or groups) named in args, or lets the user select them interactively
+func resolveProfiles(cfg *terraform.Config, profileArgs []string) ([]terraform.Profile, error) {
+	names := terraform.ExpandGroups(cfg, profileArgs)
+	if len(names) == 0 && len(excludeProfiles) > 0 {
+		// Excluding without naming profiles starts from all of them
+		names = terraform.ListProfiles(cfg)
+	} else if len(names) == 0 {
+		// No profiles specified, let user select
+		var err error
+		names, err = selectMultipleProfiles(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names, err := terraform.ExcludeProfiles(cfg, names, excludeProfiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Overlapping selectors may name a profile more than once; each profile runs only once
+	var profiles []terraform.Profile
+	seen := make(map[string]bool)
+	for _, profileName := range names {
+		if seen[profileName] {
+			continue
+		}
+		seen[profileName] = true
+
+		profile, exists := terraform.GetProfile(cfg, profileName)
+		if !exists {
+			return nil, fmt.Errorf("profile '%s' not found", profileName)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// profileNames returns the names of the given profiles
+func profileNames(profiles []terraform.Profile) []string {
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		names[i] = profile.Name
+	}
+	return names
+}
+
+// recordUsage stores the last-used time of every successfully executed profile
+func recordUsage(results []terraform.ExecutionResult) {
+	var used []string
+	for _, result := range results {
+		if result.Success {
+			used = append(used, result.ProfileName)
+		}
+	}
+	if err := terraform.RecordProfileUsage(used); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// removePlanState deletes the persisted review progress once it is no longer needed
+func removePlanState() {
+	if err := terraform.RemovePlanState(terraform.PlanStateFile); err != nil {
+		fmt.Printf("Warning: %v\n", err)
 	}
 }
 
 func init() {
-	rootCmd.AddCommand(applyCmd, planCmd, destroyCmd)
+	rootCmd.AddCommand(applyCmd, planCmd, destroyCmd, refreshCmd)
 
-	// Add -lock flag to commands that support it (apply, plan, destroy)
+	rootCmd.PersistentFlags().IntVarP(&maxConcurrency, "parallelism", "P", terraform.DefaultMaxConcurrency, "Maximum number of profiles executed concurrently")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also honors NO_COLOR and non-terminal stdout)")
+	rootCmd.PersistentFlags().StringVar(&colorPalette, "color-palette", utils.PaletteAuto, "Profile colors: auto (detected from $COLORTERM/$TERM), basic, 256, truecolor or colorblind")
+	rootCmd.PersistentFlags().StringVar(&workspaceMode, "workspace-mode", workspace.ModeSymlink, "How workspaces mirror the module: symlink, or copy for filesystems without symlink support")
+	rootCmd.PersistentFlags().BoolVar(&keepWorkspaces, "keep-workspaces", false, "Do not delete the profile workspaces; print their paths instead")
+	rootCmd.PersistentFlags().StringArrayVar(&isolateDirs, "isolate-dir", nil, "Mirror this top-level module directory with per-file symlinks in each workspace instead of sharing it (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&profileFilter, "select-filter", "", "Offer only profiles and groups matching this regular expression in the interactive selector")
+	rootCmd.PersistentFlags().StringArrayVar(&excludeProfiles, "exclude", nil, "Leave out this profile or group; without profile arguments, run all other profiles (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&binary, "binary", "", "Terraform-compatible executable to run, e.g. tofu (default: $TAPPER_TF_BINARY or terraform)")
+	rootCmd.PersistentFlags().StringVarP(&workingDir, "working-dir", "C", "", "Run in this module directory instead of the current one; relative paths in other flags resolve from it")
+	rootCmd.PersistentFlags().BoolVar(&terraform.RecursiveDiscovery, "recursive", false, "Also detect profiles of modules in subdirectories, named by module path, e.g. networking/prod")
+	rootCmd.PersistentFlags().StringVar(&workspaceDir, "workspace-dir", "", "Directory for profile workspaces (default: the module's parent, or $TAPPER_WORKSPACE_DIR)")
+
+	// Add -lock flag to commands that support it (apply, plan, destroy, refresh)
 	applyCmd.Flags().BoolP("lock", "l", true, "Lock the state file when locking is supported")
 	planCmd.Flags().BoolP("lock", "l", true, "Lock the state file when locking is supported")
 	destroyCmd.Flags().BoolP("lock", "l", true, "Lock the state file when locking is supported")
+	refreshCmd.Flags().BoolP("lock", "l", true, "Lock the state file when locking is supported")
+
+	// Add --resume flag to continue an interrupted plan review
+	applyCmd.Flags().Bool("resume", false, "Resume an interrupted plan review")
+	planCmd.Flags().Bool("resume", false, "Resume an interrupted plan review")
+	destroyCmd.Flags().Bool("resume", false, "Resume an interrupted plan review")
+	refreshCmd.Flags().Bool("resume", false, "Resume an interrupted plan review")
+
+	// Add --dry-run flag to preview apply and destroy without prompting
+	applyCmd.Flags().Bool("dry-run", false, "Run the plan phase and display the results without prompting or applying")
+	destroyCmd.Flags().Bool("dry-run", false, "Run the plan phase and display the results without prompting or destroying")
+	refreshCmd.Flags().Bool("dry-run", false, "Show the detected drift without prompting or updating state")
+	applyCmd.Flags().String("save-plan", "", "Review the plans, then save the approved ones to this file instead of applying")
+	destroyCmd.Flags().String("save-plan", "", "Review the plans, then save the approved ones to this file instead of destroying")
+	applyCmd.Flags().String("from-plan", "", "Apply the approved profiles of a plan saved with --save-plan, without re-planning")
+	destroyCmd.Flags().String("from-plan", "", "Destroy with the approved profiles of a plan saved with --save-plan, without re-planning")
+	applyCmd.Flags().Bool("summary-only", false, "Review only per-profile change counts and approve them with a single prompt")
+	destroyCmd.Flags().Bool("summary-only", false, "Review only per-profile change counts and approve them with a single prompt")
+
+	// Add output buffering and logging flags
+	for _, c := range []*cobra.Command{applyCmd, planCmd, destroyCmd, refreshCmd} {
+		c.Flags().Int("output-buffer-mb", 0, "Keep only the last N MB of output per profile in memory (0 for unlimited)")
+		c.Flags().String("log-dir", "", "Write the full output of every profile to this directory")
+		c.Flags().BoolP("quiet", "q", false, "Show a live per-profile progress line instead of terraform's output; failed profiles' output is shown at the end")
+		c.Flags().Bool("progress", false, "Show how many profiles have completed, e.g. 3/10, as each one finishes")
+		c.Flags().String("log-file", "", "Append every output line of every profile as JSON to this file")
+		c.Flags().Bool("validate-vars", false, "Check var file syntax before running terraform")
+		c.Flags().Bool("force", false, "Run even when selected profiles' backend configs point at the same state")
+		c.Flags().String("concurrency-group", terraform.ConcurrencyGroupsManifest, "Run profiles of the same group one at a time: manifest (declared concurrency_group), backend (also profiles sharing a state location) or none")
+		c.Flags().String("on-no-changes", terraform.NoChangesSkip, "Policy for profiles without changes: skip, apply or fail")
+		c.Flags().Bool("apply-unchanged", false, "Review and execute profiles whose plan has no changes instead of skipping them")
+		c.Flags().String("json-plan", "", "Also write each profile's plan as terraform show -json to this directory")
+		c.Flags().Lookup("json-plan").NoOptDefVal = terraform.DefaultJSONPlanDir
+		c.Flags().BoolP("all", "a", false, "Run every detected profile without the interactive selector")
+		c.Flags().Bool("plan-only", false, "Review the plans as usual, then exit without executing the approved profiles")
+		c.Flags().Bool("passthrough", false, "Run a single profile without plan review and exit with terraform's exit code")
+		c.Flags().StringArray("var-file", nil, "Add a shared var file after each selected profile's own, overriding its values (repeatable)")
+		c.Flags().StringArray("var", nil, "Override a variable as key=value in every selected profile, taking precedence over its var file (repeatable)")
+		c.Flags().StringArray("target", nil, "Limit the run to a resource address in every selected profile (repeatable)")
+		c.Flags().String("timestamp-format", terraform.DefaultTimestampFormat, "Go time layout of the timestamp prefixed to streamed lines, e.g. 2006-01-02T15:04:05")
+		c.Flags().Bool("no-timestamps", false, "Omit the timestamp from streamed lines")
+		c.Flags().Duration("heartbeat", terraform.DefaultHeartbeat, "Print a \"still running (elapsed)\" line for profiles silent this long (0 to disable)")
+		c.Flags().Bool("grouped", false, "Print each profile's output as one block when it finishes instead of interleaving lines as they arrive")
+		c.Flags().Bool("toggle-output", false, "Hide/show a profile's output while streaming by typing its name, or stop it with 'cancel <name>'")
+		c.Flags().Int("terraform-parallelism", 10, "Number of concurrent terraform operations per profile")
+		c.Flags().Duration("lock-timeout", 0, "How long terraform retries acquiring a held state lock (e.g. 2m)")
+		c.Flags().BoolP("auto-approve", "y", false, "Approve every profile whose plan succeeded without prompting")
+		c.Flags().String("workspace-map", "", "Write the profile-to-workspace mapping as JSON to this file (\"-\" for stdout)")
+		c.Flags().Bool("pager", false, "Open each profile's plan output in $PAGER (or less -R) during review")
+		c.Flags().Int("review-page-size", 0, "Review profiles in pages of this size, approving each page with one answer")
+		c.Flags().Int("auth-retries", terraform.DefaultAuthRetries, "Times to refresh expired cloud credentials and retry a failed profile")
+		c.Flags().String("output", "text", "Output format: text, or markdown to print the plans as a PR comment without prompting")
+		c.Flags().Duration("timeout", 0, "Maximum duration of each profile's terraform command, e.g. 30m (0 for no limit)")
+		c.Flags().Bool("reinit", false, "Always run terraform init, even when it looks up to date")
+		c.Flags().StringArray("init-arg", nil, "Extra argument for terraform init in every profile, e.g. -upgrade; forces init to run (repeatable)")
+		c.Flags().String("reason", "", "Reason for the run, recorded in logs and saved plans")
+		c.Flags().String("ticket", "", "Change ticket ID, recorded in logs and saved plans")
+	}
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"tapper/pkg/terraform"
+	"tapper/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// showPlan is a saved plan file to show instead of the current state
+	showPlan string
+	// showJSON prints terraform's machine-readable output
+	showJSON bool
+)
+
+// showCmd represents the show command
+var showCmd = &cobra.Command{
+	Use:   "show <profile>",
+	Short: "Show the current state or a saved plan of a single profile",
+	Long: `Run terraform show in the workspace of a single profile to display its current state in
+human-readable form, or with --plan a saved binary plan file, e.g. one kept by apply --save-plan.
+With --json terraform's JSON representation is printed and progress goes to stderr. Read-only.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Groups are not expanded: show reads exactly one profile
+		profile, exists := terraform.GetProfile(cfg, args[0])
+		if !exists {
+			fmt.Printf("Error: profile '%s' not found\n", args[0])
+			os.Exit(1)
+		}
+
+		// terraform runs in the workspace, so the plan needs an absolute path
+		planFile := showPlan
+		if planFile != "" {
+			if exists, _ := utils.CheckFileOrDirExists(planFile); !exists {
+				fmt.Printf("Error: plan file %s not found\n", planFile)
+				os.Exit(1)
+			}
+			if planFile, err = filepath.Abs(planFile); err != nil {
+				fmt.Printf("Error resolving plan file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Keep stdout clean for the JSON document by sending progress to stderr
+		executor := newExecutor()
+		if showJSON {
+			executor.SetProgressWriter(os.Stderr)
+		}
+		result, execErr := executor.Show(profile, planFile, showJSON, os.Stdout)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+
+		if execErr != nil {
+			fmt.Fprintf(os.Stderr, "Error running show: %v\n", execErr)
+			os.Exit(1)
+		}
+		if result.Error != nil {
+			fmt.Fprintf(os.Stderr, "Show of profile '%s' failed: %v\n", profile.Name, result.Error)
+			os.Exit(exitProfileFailure)
+		}
+	},
+}
+
+func init() {
+	showCmd.Flags().StringVar(&showPlan, "plan", "", "Show this saved plan file instead of the current state")
+	showCmd.Flags().BoolVar(&showJSON, "json", false, "Print terraform's JSON output (show -json)")
+	rootCmd.AddCommand(showCmd)
+}
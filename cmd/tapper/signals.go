@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tapper/pkg/terraform"
+)
+
+// exitInterrupted is the conventional exit code of a process stopped by Ctrl-C
+const exitInterrupted = 130
+
+// handleInterrupts cancels running profiles and removes the executor's workspaces on SIGINT or
+// SIGTERM before exiting, since os.Exit and signals skip the deferred cleanup
+func handleInterrupts(executor *terraform.Executor) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		fmt.Fprintf(os.Stderr, "\nReceived %v: stopping running profiles and cleaning up, please wait...\n", sig)
+
+		// Further signals are ignored so the cleanup is not interrupted halfway
+		go func() {
+			for range signals {
+				fmt.Fprintln(os.Stderr, "Cleanup in progress, please wait...")
+			}
+		}()
+
+		// Ctrl-C already reached terraform, which shares tapper's process group
+		executor.Interrupt(terraform.TimeoutGracePeriod, sig == os.Interrupt)
+		if err := executor.WorkspaceCleanup(nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Error cleaning up workspaces: %v\n", err)
+		}
+		fmt.Fprintln(os.Stderr, "Cleanup complete.")
+		os.Exit(exitInterrupted)
+	}()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"tapper/pkg/terraform"
+
+	"github.com/spf13/cobra"
+)
+
+// stateCmd groups the read-only state inspection commands
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect terraform state across profiles",
+}
+
+// stateListCmd represents the state list command
+var stateListCmd = &cobra.Command{
+	Use:   "list [profile...]",
+	Short: "List the resources in the state of selected profile(s)",
+	Long: `Run terraform state list in each selected profile's workspace and print the resource addresses grouped by profile.
+If no profile is specified, displays an interactive selection menu.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		filter, _ := cmd.Flags().GetString("filter")
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		profiles, err := resolveProfiles(cfg, args)
+		if err != nil {
+			fmt.Printf("Error selecting profiles: %v\n", err)
+			os.Exit(1)
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No profiles selected.")
+			return
+		}
+
+		executor := newExecutor()
+		results, execErr := executor.StateList(profiles)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+		if results == nil {
+			fmt.Printf("Error listing state: %v\n", execErr)
+			os.Exit(exitSetupError)
+		}
+
+		for _, result := range results {
+			if !result.Success {
+				continue
+			}
+			fmt.Printf("=== Profile: %s ===\n", result.ProfileName)
+			for _, address := range strings.Split(result.Stdout, "\n") {
+				address = strings.TrimSpace(address)
+				if address != "" && strings.Contains(address, filter) {
+					fmt.Println(address)
+				}
+			}
+		}
+
+		if execErr != nil {
+			fmt.Printf("Error listing state:\n%v\n", execErr)
+			os.Exit(exitProfileFailure)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateListCmd)
+
+	stateListCmd.Flags().String("filter", "", "Only show resource addresses containing this substring")
+}
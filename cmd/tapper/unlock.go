@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"tapper/pkg/terraform"
+	"tapper/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// unlockCmd represents the unlock command
+var unlockCmd = &cobra.Command{
+	Use:   "unlock <profile> <lock-id>",
+	Short: "Force-unlock a stuck state lock of a single profile",
+	Long: `Run terraform force-unlock in the workspace of a single profile, initialized with its backend config.
+Only release a lock when the run holding it is no longer active; tapper asks for confirmation first.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		profileName, lockID := args[0], args[1]
+		if strings.TrimSpace(lockID) == "" {
+			fmt.Println("Error: unlock requires a non-empty lock ID")
+			os.Exit(1)
+		}
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Groups are not expanded: unlock targets exactly one profile
+		profile, exists := terraform.GetProfile(cfg, profileName)
+		if !exists {
+			fmt.Printf("Error: profile '%s' not found\n", profileName)
+			os.Exit(1)
+		}
+
+		if !utils.IsInteractive() {
+			fmt.Printf("Error: cannot confirm the unlock: %v\n", utils.ErrNonInteractive)
+			os.Exit(1)
+		}
+		fmt.Printf("Force-unlocking the state of profile '%s' (lock ID %s) can corrupt it if the lock holder is still running.\n", profileName, lockID)
+		fmt.Print("Release the lock? (y/n): ")
+		response, err := utils.ReadLine()
+		if response = strings.ToLower(strings.TrimSpace(response)); err != nil || (response != "y" && response != "yes") {
+			fmt.Println("Unlock cancelled.")
+			return
+		}
+
+		executor := newExecutor()
+		result, execErr := executor.ForceUnlock(profile, lockID)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+
+		if execErr != nil {
+			fmt.Printf("Error unlocking state: %v\n", execErr)
+			os.Exit(1)
+		}
+		if result.Error != nil {
+			fmt.Printf("Unlock of profile '%s' failed: %v\n", profileName, result.Error)
+			os.Exit(exitProfileFailure)
+		}
+		fmt.Printf("Released lock %s of profile '%s'\n", lockID, profileName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unlockCmd)
+}
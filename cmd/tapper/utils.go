@@ -2,11 +2,47 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"tapper/pkg/terraform"
 	"tapper/pkg/utils"
 )
 
+// requireModuleDir exits unless the current directory is a terraform module. With recursive
+// discovery it may also be a monorepo root holding the modules in subdirectories.
+func requireModuleDir() {
+	if terraform.RecursiveEnabled() {
+		return
+	}
+	utils.IsActiveDir()
+}
+
+// changeWorkingDir moves into dir after checking that it is a terraform module, or a monorepo
+// root when recursive discovery is enabled
+func changeWorkingDir(dir string) error {
+	exists, err := utils.CheckDirExists(dir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	if terraform.RecursiveEnabled() {
+		return nil
+	}
+	active, err := utils.HasActiveFiles(".")
+	if err != nil {
+		return fmt.Errorf("error reading module directory: %w", err)
+	}
+	if !active {
+		return fmt.Errorf("%s does not contain any active terraform files", dir)
+	}
+	return nil
+}
+
 // selectMultipleProfiles allows the user to interactively select multiple profiles
 func selectMultipleProfiles(cfg *terraform.Config) ([]string, error) {
 	profiles := terraform.ListProfiles(cfg)
@@ -19,5 +55,41 @@ func selectMultipleProfiles(cfg *terraform.Config) ([]string, error) {
 		"Select profiles (use Tab to select multiple): ",
 		"Available Terraform profiles - Tab to select, Enter to confirm",
 	)
-	return utils.InteractiveSelect(profiles, config)
+	config.Preview = varFilePreview(cfg)
+	config.PreviewWindow = "right:50%:wrap"
+	config.Filter = profileFilter
+	if len(cfg.Groups) == 0 && len(cfg.Aliases) == 0 {
+		return utils.InteractiveSelect(profiles, config)
+	}
+
+	// Groups are listed first, then aliases labeled with their profile; selecting either
+	// selects the profiles behind it
+	hierarchy := make(map[string][]string, len(cfg.Groups)+len(cfg.Aliases))
+	items := terraform.GroupNames(cfg)
+	for _, group := range items {
+		hierarchy[group] = cfg.Groups[group]
+	}
+	for _, alias := range terraform.AliasNames(cfg) {
+		label := fmt.Sprintf("%s (%s)", alias, cfg.Aliases[alias])
+		hierarchy[label] = []string{cfg.Aliases[alias]}
+		items = append(items, label)
+	}
+	items = append(items, profiles...)
+	config.Header = "Available Terraform profiles, groups and aliases - Tab to select, Enter to confirm"
+	return utils.HierarchicalSelect(items, hierarchy, config)
+}
+
+// varFilePreview returns an fzf preview command printing the highlighted profile's var file.
+// fzf substitutes {} with the quoted item; groups have no var file and get a placeholder.
+// Alias items such as "prod (acme-prod)" preview the profile in parentheses.
+// A nested profile such as networking/prod reads networking/<vars dir>/prod.tfvars.
+func varFilePreview(cfg *terraform.Config) string {
+	varsDir := terraform.DefaultVarsDir
+	if len(cfg.Profiles) > 0 {
+		varsDir = cfg.Profiles[0].VarsDir
+	}
+	dir := utils.ShellQuote(varsDir)
+	return fmt.Sprintf(`p={}; case "$p" in *" ("*")") p="${p##* (}"; p="${p%%)}";; esac; `+
+		`f="$(dirname -- "$p")"/%s/"$(basename -- "$p")"; `+
+		`cat -- "$f.tfvars" 2>/dev/null || cat -- "$f.tfvars.json" 2>/dev/null || echo 'No var file to preview'`, dir)
 }
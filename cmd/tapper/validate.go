@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"tapper/pkg/terraform"
+
+	"github.com/spf13/cobra"
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [profile...]",
+	Short: "Run terraform validate with selected profile(s)",
+	Long: `Run terraform validate in an isolated workspace for one or more profiles.
+Validation is read-only, so no approval is required. Exits non-zero if any profile fails.
+If no profile is specified, displays an interactive selection menu.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		requireModuleDir()
+
+		cfg, err := terraform.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+
+		profiles, err := resolveProfiles(cfg, args)
+		if err != nil {
+			fmt.Printf("Error selecting profiles: %v\n", err)
+			os.Exit(1)
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No profiles selected.")
+			return
+		}
+
+		executor := newExecutor()
+
+		results, err := executor.Validate(profiles)
+		if cleanupErr := executor.WorkspaceCleanup(nil); cleanupErr != nil {
+			fmt.Printf("Warning: Error cleaning up workspaces: %v\n", cleanupErr)
+		}
+		if results == nil {
+			fmt.Printf("Error validating profiles: %v\n", err)
+			os.Exit(exitSetupError)
+		}
+
+		fmt.Println("\nValidate summary:")
+		for _, result := range results {
+			if result.Success {
+				fmt.Printf("  ✅ %s (%v)\n", result.ProfileName, result.Duration)
+			} else {
+				fmt.Printf("  ❌ %s: %v\n", result.ProfileName, result.Error)
+			}
+		}
+
+		if terraform.HasFailures(results) {
+			os.Exit(exitProfileFailure)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// version is tapper's release version, set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+// versionInfo is the --json output of the version command
+type versionInfo struct {
+	Tapper        string `json:"tapper"`
+	Terraform     string `json:"terraform,omitempty"`
+	TerraformPath string `json:"terraform_path,omitempty"`
+	TerraformErr  string `json:"terraform_error,omitempty"`
+}
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the tapper and terraform versions",
+	Long: `Print tapper's version and the version of the configured terraform binary, e.g. for bug reports.
+tapper's version is still printed when terraform is not installed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		info := versionInfo{Tapper: version}
+		tfVersion, path, err := terraformVersion()
+		if err != nil {
+			info.TerraformErr = err.Error()
+		} else {
+			info.Terraform = tfVersion
+			info.TerraformPath = path
+		}
+
+		if asJSON {
+			data, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				fmt.Printf("Error encoding version: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		fmt.Printf("tapper %s\n", info.Tapper)
+		if info.TerraformErr != "" {
+			fmt.Printf("terraform: not available (%s)\n", info.TerraformErr)
+			return
+		}
+		fmt.Printf("%s (%s)\n", info.Terraform, info.TerraformPath)
+	},
+}
+
+// terraformVersion returns the first line of the configured terraform binary's version output and its path
+func terraformVersion() (string, string, error) {
+	name := terraformBinary()
+	path, err := checkBinary(name)()
+	if err != nil {
+		return "", "", err
+	}
+
+	output, err := exec.Command(path, "version").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("error running %s version: %w", name, err)
+	}
+	return strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)[0], path, nil
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().Bool("json", false, "Print the versions as JSON")
+}
@@ -0,0 +1,60 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"tapper/pkg/utils"
+)
+
+// BackendEnvPrefix marks environment variables that supply backend config values,
+// e.g. TAPPER_BACKEND_BUCKET=my-state becomes -backend-config=bucket=my-state
+const BackendEnvPrefix = "TAPPER_BACKEND_"
+
+// BackendConfigFromEnv returns sorted key=value backend config pairs from TAPPER_BACKEND_* variables
+func BackendConfigFromEnv() []string {
+	var values []string
+	for _, entry := range os.Environ() {
+		name, value, found := strings.Cut(entry, "=")
+		if !found || !strings.HasPrefix(name, BackendEnvPrefix) {
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimPrefix(name, BackendEnvPrefix))
+		if key == "" {
+			continue
+		}
+		values = append(values, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	sort.Strings(values)
+	return values
+}
+
+// ResolveBackend falls back to TAPPER_BACKEND_* values when the backend config file is missing
+func (cb *CommandBuilder) ResolveBackend() error {
+	backendConfigPath := cb.GetBackendConfigPath()
+	if backendConfigPath != "" {
+		exists, err := utils.CheckFileOrDirExists(backendConfigPath)
+		if err != nil {
+			return fmt.Errorf("error checking backend config file: %w", err)
+		}
+		if exists {
+			return nil
+		}
+	}
+
+	values := BackendConfigFromEnv()
+	if len(values) == 0 {
+		if backendConfigPath == "" {
+			return fmt.Errorf("no backend config file and no %s* variables set", BackendEnvPrefix)
+		}
+		return fmt.Errorf("backend config file not found: %s", backendConfigPath)
+	}
+
+	cb.BackendConfig = ""
+	cb.BackendValues = values
+	return nil
+}
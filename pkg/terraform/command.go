@@ -1,9 +1,14 @@
 package terraform
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"tapper/pkg/utils"
 )
@@ -12,17 +17,49 @@ import (
 type CommandBuilder struct {
 	WorkingDir    string
 	BackendConfig string
+	BackendValues []string
 	VarFile       string
 	BackendDir    string
 	VarsDir       string
 	Targets       []string
+	ExtraVarFiles []string // Shared var files passed after the profile's var file
+	Vars          []string // key=value variable overrides, passed after the var file so they win
+	InitArgs      []string // Extra arguments for terraform init, e.g. -upgrade
+	Lock          *bool
+	Parallelism   int
+	NoColor       bool
+	Context       context.Context   // Cancels the command, e.g. on timeout, when set
+	Env           map[string]string // Variables added to the inherited environment, overriding it
+	Binary        string            // Terraform-compatible executable, e.g. "tofu"
 }
 
+// DefaultBinary is the executable run when no other binary is configured
+const DefaultBinary = "terraform"
+
+// Binary is the terraform-compatible executable used by new command builders
+var Binary = DefaultBinary
+
+// SetBinary selects the executable used for terraform commands, e.g. "tofu" for OpenTofu
+func SetBinary(name string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found in PATH; install it or choose another binary with --binary or TAPPER_TF_BINARY", name)
+	}
+	Binary = name
+	return nil
+}
+
+// TimeoutGracePeriod is how long terraform may shut down gracefully after its context is cancelled
+const TimeoutGracePeriod = 30 * time.Second
+
+// PlanFileName is the saved plan file written inside each profile workspace
+const PlanFileName = "tapper.tfplan"
+
 // NewCommandBuilder creates a new terraform command builder
 func NewCommandBuilder() *CommandBuilder {
 	return &CommandBuilder{
-		BackendDir: "backend",
-		VarsDir:    "vars",
+		BackendDir: DefaultBackendDir,
+		VarsDir:    DefaultVarsDir,
+		Binary:     Binary,
 	}
 }
 
@@ -31,17 +68,27 @@ func NewCommandBuilder() *CommandBuilder {
 func (cb *CommandBuilder) BuildCommandFromProfile(profile Profile, workspacePath string, execOpts *ExecutionOptions) (*exec.Cmd, error) {
 	// Configure the builder with profile settings
 	cb.WithWorkingDir(workspacePath).
+		WithEnv(profile.Env).
 		WithVarFile(profile.VarFile).
-		WithVarsDir(profile.VarsDir)
+		WithVarsDir(profile.VarsDir).
+		WithTargets(execOpts.Targets).
+		WithExtraVarFiles(execOpts.VarFiles).
+		WithVars(execOpts.Vars).
+		WithLock(profile.Lock).
+		WithParallelism(profile.Parallelism)
 
 	// Validate command type
 	switch execOpts.Command {
-	case "plan", "apply", "destroy":
+	case "plan", "apply", "destroy", "refresh", "output", "validate", "state":
 		// Valid commands
 	default:
 		return nil, fmt.Errorf("unsupported command: %s", execOpts.Command)
 	}
 
+	if err := validateUserArgs(execOpts); err != nil {
+		return nil, err
+	}
+
 	// Build the command using the generic method
 	cmd := cb.buildTerraformCommand(execOpts)
 
@@ -56,37 +103,173 @@ func (cb *CommandBuilder) BuildCommandFromProfile(profile Profile, workspacePath
 // buildTerraformCommand builds a generic terraform command with common arguments
 func (cb *CommandBuilder) buildTerraformCommand(execOpts *ExecutionOptions) *exec.Cmd {
 	args := []string{execOpts.Command}
-
-	// Add var file if specified
-	if cb.VarFile != "" {
-		varFilePath := filepath.Join(cb.VarsDir, cb.VarFile)
-		args = append(args, fmt.Sprintf("--var-file=%s", varFilePath))
+	if execOpts.Command == REFRESH_COMMAND {
+		args = []string{"apply", "-refresh-only"}
 	}
 
-	// Add targets if specified
-	for _, target := range cb.Targets {
-		args = append(args, fmt.Sprintf("--target=%s", target))
-	}
+	// Only planning commands accept variables, targets and state flags
+	if isPlanningCommand(execOpts.Command) {
+		// Add var file if specified
+		if cb.VarFile != "" {
+			varFilePath := filepath.Join(cb.VarsDir, cb.VarFile)
+			args = append(args, fmt.Sprintf("--var-file=%s", varFilePath))
+		}
 
-	// Add command-specific dry run flags
-	switch execOpts.Command {
-	case "plan":
-		args = append(args, "--detailed-exitcode")
-	case "apply", "destroy":
-		if !execOpts.DryRun {
-			args = append(args, "--auto-approve")
+		// Shared var files follow the profile's own, so their values override it
+		for _, varFile := range cb.ExtraVarFiles {
+			args = append(args, fmt.Sprintf("--var-file=%s", varFile))
+		}
+
+		// Add variable overrides after the var files: terraform lets the last definition win
+		for _, v := range cb.Vars {
+			args = append(args, fmt.Sprintf("-var=%s", v))
 		}
+
+		// Add targets if specified
+		for _, target := range cb.Targets {
+			args = append(args, fmt.Sprintf("--target=%s", target))
+		}
+
+		// Add command-specific dry run flags, unless the user supplied their own
+		switch execOpts.Command {
+		case "plan":
+			if !hasFlag(execOpts.Args, "detailed-exitcode") {
+				args = append(args, "--detailed-exitcode")
+			}
+		case "apply", "destroy", "refresh":
+			if !execOpts.DryRun && !hasFlag(execOpts.Args, "auto-approve") {
+				args = append(args, "--auto-approve")
+			}
+		}
+
+		// JSON plans are rendered from the saved plan
+		if execOpts.SavePlan || (execOpts.JSONPlanDir != "" && execOpts.Command == PREVIEW_COMMAND) {
+			args = append(args, fmt.Sprintf("-out=%s", PlanFileName))
+		}
+
+		args = cb.appendProfileDefaults(args, execOpts.Args)
+	}
+
+	// State subcommands do not accept -no-color and print no color anyway
+	if cb.NoColor && execOpts.Command != STATE_COMMAND {
+		args = append(args, "-no-color")
 	}
 
 	// Apply external args
 	args = append(args, execOpts.Args...)
 
-	cmd := exec.Command("terraform", args...)
-	if cb.WorkingDir != "" {
-		cmd.Dir = cb.WorkingDir
+	return cb.terraformCommand(args)
+}
+
+// BuildApplyPlanCommand builds a terraform apply command executing a previously saved plan file
+func (cb *CommandBuilder) BuildApplyPlanCommand(profile Profile, workspacePath, planFile string, execOpts *ExecutionOptions) *exec.Cmd {
+	cb.WithWorkingDir(workspacePath).
+		WithEnv(profile.Env).
+		WithLock(profile.Lock).
+		WithParallelism(profile.Parallelism)
+
+	// Saved plans already carry variables and targets, and apply without prompting
+	args := cb.appendProfileDefaults([]string{"apply"}, execOpts.Args)
+	if cb.NoColor {
+		args = append(args, "-no-color")
 	}
+	args = append(args, execOpts.Args...)
+	args = append(args, planFile)
 
-	return cmd
+	return cb.terraformCommand(args)
+}
+
+// BuildImportCommand builds a terraform import command bringing the resource with id under address
+func (cb *CommandBuilder) BuildImportCommand(profile Profile, workspacePath, address, id string) (*exec.Cmd, error) {
+	cb.WithWorkingDir(workspacePath).
+		WithEnv(profile.Env).
+		WithVarFile(profile.VarFile).
+		WithVarsDir(profile.VarsDir).
+		WithLock(profile.Lock)
+
+	if err := cb.validateVarFile(); err != nil {
+		return nil, err
+	}
+
+	// Import evaluates the configuration, so it needs the profile's variables and overrides
+	args := []string{IMPORT_COMMAND, "-input=false"}
+	if cb.VarFile != "" {
+		args = append(args, fmt.Sprintf("--var-file=%s", filepath.Join(cb.VarsDir, cb.VarFile)))
+	}
+	for _, varFile := range cb.ExtraVarFiles {
+		args = append(args, fmt.Sprintf("--var-file=%s", varFile))
+	}
+	for _, v := range cb.Vars {
+		args = append(args, fmt.Sprintf("-var=%s", v))
+	}
+	if cb.Lock != nil {
+		args = append(args, fmt.Sprintf("-lock=%t", *cb.Lock))
+	}
+	if cb.NoColor {
+		args = append(args, "-no-color")
+	}
+	args = append(args, address, id)
+
+	return cb.terraformCommand(args), nil
+}
+
+// BuildConsoleCommand builds an interactive terraform console command with the profile's var file
+func (cb *CommandBuilder) BuildConsoleCommand(profile Profile, workspacePath string) (*exec.Cmd, error) {
+	cb.WithWorkingDir(workspacePath).
+		WithEnv(profile.Env).
+		WithVarFile(profile.VarFile).
+		WithVarsDir(profile.VarsDir)
+
+	if err := cb.validateVarFile(); err != nil {
+		return nil, err
+	}
+
+	args := []string{CONSOLE_COMMAND}
+	if cb.VarFile != "" {
+		args = append(args, fmt.Sprintf("--var-file=%s", filepath.Join(cb.VarsDir, cb.VarFile)))
+	}
+	return cb.terraformCommand(args), nil
+}
+
+// BuildGraphCommand builds a terraform graph command. The graph is built from the configuration
+// alone, so no var files are passed.
+func (cb *CommandBuilder) BuildGraphCommand(profile Profile, workspacePath string) *exec.Cmd {
+	cb.WithWorkingDir(workspacePath).WithEnv(profile.Env)
+	return cb.terraformCommand([]string{GRAPH_COMMAND})
+}
+
+// BuildShowCommand builds a terraform show command for the profile's state, or for planFile if set
+func (cb *CommandBuilder) BuildShowCommand(profile Profile, workspacePath, planFile string, asJSON bool) *exec.Cmd {
+	cb.WithWorkingDir(workspacePath).WithEnv(profile.Env)
+
+	args := []string{SHOW_COMMAND}
+	if asJSON {
+		args = append(args, "-json")
+	} else if cb.NoColor {
+		args = append(args, "-no-color")
+	}
+	if planFile != "" {
+		args = append(args, planFile)
+	}
+	return cb.terraformCommand(args)
+}
+
+// BuildForceUnlockCommand builds a terraform force-unlock command for lockID. tapper asks for
+// confirmation itself, so terraform's own prompt is skipped with -force.
+func (cb *CommandBuilder) BuildForceUnlockCommand(profile Profile, workspacePath, lockID string) *exec.Cmd {
+	cb.WithWorkingDir(workspacePath).WithEnv(profile.Env)
+	return cb.terraformCommand([]string{FORCE_UNLOCK_COMMAND, "-force", lockID})
+}
+
+// appendProfileDefaults adds the profile's lock and parallelism defaults unless explicitly overridden
+func (cb *CommandBuilder) appendProfileDefaults(args, explicitArgs []string) []string {
+	if cb.Lock != nil && !hasFlag(explicitArgs, "lock") {
+		args = append(args, fmt.Sprintf("-lock=%t", *cb.Lock))
+	}
+	if cb.Parallelism > 0 && !hasFlag(explicitArgs, "parallelism") {
+		args = append(args, fmt.Sprintf("-parallelism=%d", cb.Parallelism))
+	}
+	return args
 }
 
 // GetBackendConfigPath returns the full path to the backend config file
@@ -120,6 +303,12 @@ func (cb *CommandBuilder) WithVarFile(varFile string) *CommandBuilder {
 	return cb
 }
 
+// WithBackendValues sets key=value backend config pairs passed to init
+func (cb *CommandBuilder) WithBackendValues(values []string) *CommandBuilder {
+	cb.BackendValues = values
+	return cb
+}
+
 // WithBackendDir sets the backend directory
 func (cb *CommandBuilder) WithBackendDir(dir string) *CommandBuilder {
 	cb.BackendDir = dir
@@ -138,6 +327,42 @@ func (cb *CommandBuilder) WithTargets(targets []string) *CommandBuilder {
 	return cb
 }
 
+// WithExtraVarFiles sets the shared var files passed after the profile's var file
+func (cb *CommandBuilder) WithExtraVarFiles(varFiles []string) *CommandBuilder {
+	cb.ExtraVarFiles = varFiles
+	return cb
+}
+
+// WithVars sets the key=value variable overrides
+func (cb *CommandBuilder) WithVars(vars []string) *CommandBuilder {
+	cb.Vars = vars
+	return cb
+}
+
+// WithInitArgs sets extra arguments appended to terraform init
+func (cb *CommandBuilder) WithInitArgs(args []string) *CommandBuilder {
+	cb.InitArgs = args
+	return cb
+}
+
+// WithLock sets the default state locking behavior
+func (cb *CommandBuilder) WithLock(lock *bool) *CommandBuilder {
+	cb.Lock = lock
+	return cb
+}
+
+// WithParallelism sets the default terraform parallelism
+func (cb *CommandBuilder) WithParallelism(parallelism int) *CommandBuilder {
+	cb.Parallelism = parallelism
+	return cb
+}
+
+// WithNoColor disables colored terraform output
+func (cb *CommandBuilder) WithNoColor(noColor bool) *CommandBuilder {
+	cb.NoColor = noColor
+	return cb
+}
+
 // BuildInitCommand builds a terraform init command
 func (cb *CommandBuilder) BuildInitCommand() *exec.Cmd {
 	args := []string{"init"}
@@ -146,14 +371,113 @@ func (cb *CommandBuilder) BuildInitCommand() *exec.Cmd {
 		backendConfigPath := filepath.Join(cb.BackendDir, cb.BackendConfig)
 		args = append(args, fmt.Sprintf("--backend-config=%s", backendConfigPath))
 	}
+	for _, value := range cb.BackendValues {
+		args = append(args, fmt.Sprintf("--backend-config=%s", value))
+	}
+
+	// Providers and modules are installed once per module, so only backend options apply here
+	initArgs, _ := splitInitArgs(cb.InitArgs)
+
+	// -migrate-state cannot be combined with -reconfigure
+	if !hasFlag(initArgs, "reconfigure") && !hasFlag(initArgs, "migrate-state") {
+		args = append(args, "--reconfigure")
+	}
+	if cb.NoColor {
+		args = append(args, "-no-color")
+	}
+	args = append(args, initArgs...)
 
-	args = append(args, "--reconfigure")
+	return cb.terraformCommand(args)
+}
+
+// BuildInstallCommand builds a terraform init that only installs providers and modules,
+// leaving the backend unconfigured
+func (cb *CommandBuilder) BuildInstallCommand() *exec.Cmd {
+	args := []string{"init", "-backend=false"}
+	if cb.NoColor {
+		args = append(args, "-no-color")
+	}
+	// Backend options only apply to the workspace inits
+	_, installArgs := splitInitArgs(cb.InitArgs)
+	args = append(args, installArgs...)
+	return cb.terraformCommand(args)
+}
+
+// splitInitArgs separates init arguments configuring the backend from those for installing
+// providers and modules
+func splitInitArgs(args []string) (backendArgs, installArgs []string) {
+	for _, arg := range args {
+		if isBackendInitArg(arg) {
+			backendArgs = append(backendArgs, arg)
+		} else {
+			installArgs = append(installArgs, arg)
+		}
+	}
+	return backendArgs, installArgs
+}
+
+// isBackendInitArg reports whether an init argument configures the backend
+func isBackendInitArg(arg string) bool {
+	for _, name := range []string{"backend", "backend-config", "reconfigure", "migrate-state", "force-copy"} {
+		if hasFlag([]string{arg}, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildFmtCommand builds a terraform fmt command; check reports files needing formatting without rewriting them
+func (cb *CommandBuilder) BuildFmtCommand(check bool) *exec.Cmd {
+	args := []string{"fmt"}
+	if check {
+		args = append(args, "-check", "-diff")
+	}
+
+	return cb.terraformCommand(args)
+}
+
+// WithContext ties the built commands to ctx, interrupting them once it is done
+func (cb *CommandBuilder) WithContext(ctx context.Context) *CommandBuilder {
+	cb.Context = ctx
+	return cb
+}
+
+// WithEnv sets environment variables that override the inherited environment
+func (cb *CommandBuilder) WithEnv(env map[string]string) *CommandBuilder {
+	cb.Env = env
+	return cb
+}
+
+// terraformCommand creates the terraform command for args in the builder's working directory
+func (cb *CommandBuilder) terraformCommand(args []string) *exec.Cmd {
+	binary := cb.Binary
+	if binary == "" {
+		binary = Binary
+	}
+
+	var cmd *exec.Cmd
+	if cb.Context != nil {
+		cmd = exec.CommandContext(cb.Context, binary, args...)
+		// Interrupt first so terraform can release state locks, kill after the grace period
+		cmd.Cancel = func() error {
+			return cmd.Process.Signal(os.Interrupt)
+		}
+		cmd.WaitDelay = TimeoutGracePeriod
+	} else {
+		cmd = exec.Command(binary, args...)
+	}
 
-	cmd := exec.Command("terraform", args...)
 	if cb.WorkingDir != "" {
 		cmd.Dir = cb.WorkingDir
 	}
 
+	// Each command gets its own environment; later entries win over the inherited ones
+	if len(cb.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, name := range utils.EnvNames(cb.Env) {
+			cmd.Env = append(cmd.Env, name+"="+cb.Env[name])
+		}
+	}
 	return cmd
 }
 
@@ -184,3 +508,50 @@ func (cb *CommandBuilder) validateVarFile() error {
 	}
 	return nil
 }
+
+// isPlanningCommand reports whether a terraform command plans changes and accepts variables
+func isPlanningCommand(command string) bool {
+	switch command {
+	case "plan", "apply", "destroy", "refresh":
+		return true
+	}
+	return false
+}
+
+// flagEnabled reports whether the boolean terraform flag name is set in args, honoring the
+// last occurrence and explicit values such as -auto-approve=false
+func flagEnabled(args []string, name string) bool {
+	enabled := false
+	for _, arg := range args {
+		flag := strings.TrimLeft(arg, "-")
+		if arg == flag {
+			continue
+		}
+		switch {
+		case flag == name:
+			enabled = true
+		case strings.HasPrefix(flag, name+"="):
+			enabled, _ = strconv.ParseBool(strings.TrimPrefix(flag, name+"="))
+		}
+	}
+	return enabled
+}
+
+// validateUserArgs rejects user arguments that would conflict with flags tapper must control
+func validateUserArgs(execOpts *ExecutionOptions) error {
+	if (execOpts.SavePlan || execOpts.JSONPlanDir != "") && hasFlag(execOpts.Args, "out") {
+		return fmt.Errorf("-out cannot be passed to %s: tapper saves the reviewed plan to %s itself", execOpts.Command, PlanFileName)
+	}
+	return nil
+}
+
+// hasFlag reports whether args contain the terraform flag name in single- or double-dash form
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		flag := strings.TrimLeft(arg, "-")
+		if arg != flag && (flag == name || strings.HasPrefix(flag, name+"=")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildTerraformCommandProfileDefaults(t *testing.T) {
+	lock := false
+	cb := NewCommandBuilder().WithLock(&lock).WithParallelism(2)
+
+	cmd := cb.buildTerraformCommand(&ExecutionOptions{Command: "apply"})
+	args := strings.Join(cmd.Args, " ")
+	if !strings.Contains(args, "-lock=false") || !strings.Contains(args, "-parallelism=2") {
+		t.Errorf("Expected profile defaults in args, got: %s", args)
+	}
+
+	// Explicit flags take precedence over profile defaults
+	cmd = cb.buildTerraformCommand(&ExecutionOptions{Command: "apply", Args: []string{"-lock=true", "--parallelism=8"}})
+	args = strings.Join(cmd.Args, " ")
+	if strings.Contains(args, "-lock=false") || strings.Contains(args, "-parallelism=2") {
+		t.Errorf("Expected explicit flags to override profile defaults, got: %s", args)
+	}
+
+	// -lock-timeout is not the -lock flag
+	if hasFlag([]string{"-lock-timeout=10s"}, "lock") {
+		t.Error("Expected -lock-timeout not to match the lock flag")
+	}
+}
+
+func TestResolveBackendFromEnv(t *testing.T) {
+	t.Setenv("TAPPER_BACKEND_BUCKET", "state-bucket")
+	t.Setenv("TAPPER_BACKEND_KEY", "dev/terraform.tfstate")
+
+	cb := NewCommandBuilder().WithWorkingDir(t.TempDir()).WithBackendConfig("dev.tfbackend")
+	if err := cb.ResolveBackend(); err != nil {
+		t.Fatalf("Expected env backend fallback, got error: %v", err)
+	}
+
+	args := strings.Join(cb.BuildInitCommand().Args, " ")
+	if strings.Contains(args, "dev.tfbackend") {
+		t.Errorf("Expected missing backend file to be dropped, got: %s", args)
+	}
+	if !strings.Contains(args, "--backend-config=bucket=state-bucket --backend-config=key=dev/terraform.tfstate") {
+		t.Errorf("Expected env backend values in args, got: %s", args)
+	}
+}
+
+func TestBuildTerraformCommandUserOverrides(t *testing.T) {
+	cb := NewCommandBuilder()
+
+	cmd := cb.buildTerraformCommand(&ExecutionOptions{Command: "apply", Args: []string{"-auto-approve=false"}})
+	args := strings.Join(cmd.Args, " ")
+	if strings.Contains(args, "--auto-approve") {
+		t.Errorf("Expected the user's -auto-approve to replace tapper's, got: %s", args)
+	}
+
+	cmd = cb.buildTerraformCommand(&ExecutionOptions{Command: "plan", Args: []string{"-detailed-exitcode=false"}})
+	if flagEnabled(cmd.Args[1:], "detailed-exitcode") {
+		t.Errorf("Expected -detailed-exitcode to be disabled, got: %v", cmd.Args)
+	}
+
+	cmd = cb.buildTerraformCommand(&ExecutionOptions{Command: "plan"})
+	if strings.Count(strings.Join(cmd.Args, " "), "detailed-exitcode") != 1 || !flagEnabled(cmd.Args[1:], "detailed-exitcode") {
+		t.Errorf("Expected a single --detailed-exitcode, got: %v", cmd.Args)
+	}
+
+	if err := validateUserArgs(&ExecutionOptions{Command: "plan", SavePlan: true, Args: []string{"-out=other.tfplan"}}); err == nil {
+		t.Error("Expected -out to be rejected when tapper saves the plan")
+	}
+}
+
+func TestCommandBuilderEnv(t *testing.T) {
+	t.Setenv("AWS_PROFILE", "inherited")
+
+	cmd := NewCommandBuilder().WithEnv(map[string]string{"AWS_PROFILE": "dev", "TF_VAR_region": "eu-west-1"}).
+		buildTerraformCommand(&ExecutionOptions{Command: "plan"})
+
+	env := make(map[string]string)
+	for _, entry := range cmd.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		env[name] = value
+	}
+	if env["AWS_PROFILE"] != "dev" || env["TF_VAR_region"] != "eu-west-1" {
+		t.Errorf("Expected profile variables to override the environment, got AWS_PROFILE=%q TF_VAR_region=%q", env["AWS_PROFILE"], env["TF_VAR_region"])
+	}
+
+	// Commands without profile variables inherit the environment untouched
+	if cmd := NewCommandBuilder().buildTerraformCommand(&ExecutionOptions{Command: "plan"}); cmd.Env != nil {
+		t.Errorf("Expected inherited environment, got: %v", cmd.Env)
+	}
+}
+
+func TestBuildTerraformCommandRefresh(t *testing.T) {
+	cmd := NewCommandBuilder().buildTerraformCommand(&ExecutionOptions{Command: REFRESH_COMMAND})
+	args := strings.Join(cmd.Args[1:], " ")
+	if args != "apply -refresh-only --auto-approve" {
+		t.Errorf("Expected refresh to run apply -refresh-only, got: %s", args)
+	}
+}
+
+func TestSetBinary(t *testing.T) {
+	defer func(previous string) { Binary = previous }(Binary)
+
+	if err := SetBinary("tapper-missing-binary"); err == nil {
+		t.Error("Expected an error for a binary that is not in PATH")
+	}
+	if Binary != DefaultBinary {
+		t.Errorf("Expected the default binary to be kept, got: %s", Binary)
+	}
+
+	if err := SetBinary("sh"); err != nil {
+		t.Fatalf("SetBinary failed: %v", err)
+	}
+	cmd := NewCommandBuilder().buildTerraformCommand(&ExecutionOptions{Command: "plan"})
+	if cmd.Args[0] != "sh" {
+		t.Errorf("Expected commands to run the configured binary, got: %s", cmd.Args[0])
+	}
+}
+
+func TestBuildImportCommand(t *testing.T) {
+	lock := false
+	profile := Profile{Name: "dev", Lock: &lock}
+
+	cmd, err := NewCommandBuilder().WithNoColor(true).BuildImportCommand(profile, "/tmp/ws", "aws_s3_bucket.logs", "my-logs")
+	if err != nil {
+		t.Fatalf("BuildImportCommand failed: %v", err)
+	}
+	args := strings.Join(cmd.Args[1:], " ")
+	if args != "import -input=false -lock=false -no-color aws_s3_bucket.logs my-logs" {
+		t.Errorf("Unexpected import arguments: %s", args)
+	}
+	if cmd.Dir != "/tmp/ws" {
+		t.Errorf("Expected the workspace as working directory, got: %s", cmd.Dir)
+	}
+
+	cmd, err = NewCommandBuilder().WithExtraVarFiles([]string{"/shared.tfvars"}).WithVars([]string{"region=eu-west-1"}).
+		BuildImportCommand(Profile{Name: "dev"}, "/tmp/ws", "aws_s3_bucket.logs", "my-logs")
+	if err != nil {
+		t.Fatalf("BuildImportCommand failed: %v", err)
+	}
+	if args := strings.Join(cmd.Args[1:], " "); args != "import -input=false --var-file=/shared.tfvars -var=region=eu-west-1 aws_s3_bucket.logs my-logs" {
+		t.Errorf("Expected shared var files and overrides before the address, got: %s", args)
+	}
+}
+
+func TestBuildForceUnlockCommand(t *testing.T) {
+	cmd := NewCommandBuilder().BuildForceUnlockCommand(Profile{Name: "dev"}, "/tmp/ws", "abc-123")
+	if args := strings.Join(cmd.Args[1:], " "); args != "force-unlock -force abc-123" {
+		t.Errorf("Unexpected force-unlock arguments: %s", args)
+	}
+}
+
+func TestBuildTerraformCommandVars(t *testing.T) {
+	cb := NewCommandBuilder().WithVarFile("dev.tfvars").WithVars([]string{"region=eu-west-1", "tags={a=1}"})
+
+	args := strings.Join(cb.buildTerraformCommand(&ExecutionOptions{Command: "plan"}).Args, " ")
+	want := "--var-file=vars/dev.tfvars -var=region=eu-west-1 -var=tags={a=1}"
+	if !strings.Contains(args, want) {
+		t.Errorf("Expected variable overrides after the var file (%s), got: %s", want, args)
+	}
+
+	e := &Executor{}
+	if err := e.SetVars([]string{"region=us-east-1", "empty="}); err != nil {
+		t.Errorf("SetVars() unexpected error: %v", err)
+	}
+	for _, invalid := range []string{"region", "=value", "bad key=1"} {
+		if err := e.SetVars([]string{invalid}); err == nil {
+			t.Errorf("SetVars(%q) expected an error", invalid)
+		}
+	}
+}
+
+func TestBuildTerraformCommandExtraVarFiles(t *testing.T) {
+	dir := t.TempDir()
+	global := filepath.Join(dir, "global.tfvars")
+	if err := os.WriteFile(global, []byte("region = \"eu-west-1\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Executor{}
+	if err := e.SetVarFiles([]string{filepath.Join(dir, "missing.tfvars")}); err == nil {
+		t.Error("SetVarFiles() expected an error for a missing file")
+	}
+	if err := e.SetVarFiles([]string{global}); err != nil {
+		t.Fatalf("SetVarFiles() unexpected error: %v", err)
+	}
+
+	cb := NewCommandBuilder().WithVarFile("dev.tfvars").WithExtraVarFiles(e.VarFiles).WithVars([]string{"region=us-east-1"})
+	args := strings.Join(cb.buildTerraformCommand(&ExecutionOptions{Command: "plan"}).Args, " ")
+	want := "--var-file=vars/dev.tfvars --var-file=" + global + " -var=region=us-east-1"
+	if !strings.Contains(args, want) {
+		t.Errorf("Expected profile var file, shared var file, then overrides (%s), got: %s", want, args)
+	}
+}
+
+func TestBuildConsoleCommand(t *testing.T) {
+	cmd, err := NewCommandBuilder().BuildConsoleCommand(Profile{Name: "dev"}, "/tmp/ws")
+	if err != nil {
+		t.Fatalf("BuildConsoleCommand failed: %v", err)
+	}
+	if args := strings.Join(cmd.Args[1:], " "); args != "console" || cmd.Dir != "/tmp/ws" {
+		t.Errorf("Unexpected console command: %s in %s", args, cmd.Dir)
+	}
+
+	if _, err := NewCommandBuilder().BuildConsoleCommand(Profile{Name: "dev", VarFile: "missing.tfvars"}, "/tmp/ws"); err == nil {
+		t.Error("Expected an error for a missing var file")
+	}
+}
+
+func TestBuildGraphCommand(t *testing.T) {
+	cmd := NewCommandBuilder().BuildGraphCommand(Profile{Name: "dev", VarFile: "dev.tfvars"}, "/tmp/ws")
+	if args := strings.Join(cmd.Args[1:], " "); args != "graph" || cmd.Dir != "/tmp/ws" {
+		t.Errorf("Unexpected graph command: %s in %s", args, cmd.Dir)
+	}
+}
+
+func TestBuildShowCommand(t *testing.T) {
+	cmd := NewCommandBuilder().BuildShowCommand(Profile{Name: "dev"}, "/tmp/ws", "", false)
+	if args := strings.Join(cmd.Args[1:], " "); args != "show" {
+		t.Errorf("Unexpected show command: %s", args)
+	}
+
+	cmd = NewCommandBuilder().BuildShowCommand(Profile{Name: "dev"}, "/tmp/ws", "/plans/dev.tfplan", true)
+	if args := strings.Join(cmd.Args[1:], " "); args != "show -json /plans/dev.tfplan" {
+		t.Errorf("Unexpected show command for a plan: %s", args)
+	}
+}
+
+func TestBuildInitCommandInitArgs(t *testing.T) {
+	cb := NewCommandBuilder().WithInitArgs([]string{"-upgrade", "-migrate-state"})
+
+	args := strings.Join(cb.BuildInitCommand().Args[1:], " ")
+	if args != "init -migrate-state" {
+		t.Errorf("Expected init args without --reconfigure next to -migrate-state, got: %s", args)
+	}
+
+	cb.WithInitArgs([]string{"-upgrade"})
+	if args := strings.Join(cb.BuildInitCommand().Args[1:], " "); args != "init --reconfigure" {
+		t.Errorf("Expected -upgrade to be left to the module install, got: %s", args)
+	}
+
+	cb.WithInitArgs([]string{"-upgrade", "-backend-config=key=x", "-migrate-state"})
+	if args := strings.Join(cb.BuildInstallCommand().Args[1:], " "); args != "init -backend=false -upgrade" {
+		t.Errorf("Expected only non-backend init args in the install, got: %s", args)
+	}
+}
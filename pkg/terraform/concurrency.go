@@ -0,0 +1,135 @@
+package terraform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"tapper/pkg/utils"
+)
+
+// Concurrency group modes: which profiles must not run at the same time
+const (
+	ConcurrencyGroupsManifest = "manifest" // Only groups declared with concurrency_group in tapper.json
+	ConcurrencyGroupsBackend  = "backend"  // Declared groups, plus profiles sharing a backend state location
+	ConcurrencyGroupsNone     = "none"     // Run every profile in parallel
+)
+
+// concurrencyGroups returns the group of every profile that must run sequentially with the others
+// in its group; profiles without a group are absent and run fully in parallel
+func concurrencyGroups(profiles []Profile, mode string) map[string]string {
+	groups := make(map[string]string)
+	if mode == ConcurrencyGroupsNone {
+		return groups
+	}
+
+	for _, profile := range profiles {
+		if profile.ConcurrencyGroup != "" {
+			groups[profile.Name] = profile.ConcurrencyGroup
+			continue
+		}
+		if mode != ConcurrencyGroupsBackend || profile.BackendConfig == "" {
+			continue
+		}
+		if location := backendStateLocation(profile); location != "" {
+			groups[profile.Name] = "backend:" + location
+		}
+	}
+
+	// A group of one profile serializes nothing
+	members := make(map[string]int)
+	for _, group := range groups {
+		members[group]++
+	}
+	for name, group := range groups {
+		if members[group] < 2 {
+			delete(groups, name)
+		}
+	}
+	return groups
+}
+
+// groupLocks returns one mutex per concurrency group
+func groupLocks(groups map[string]string) map[string]*sync.Mutex {
+	locks := make(map[string]*sync.Mutex)
+	for _, group := range groups {
+		if _, exists := locks[group]; !exists {
+			locks[group] = &sync.Mutex{}
+		}
+	}
+	return locks
+}
+
+// backendStateLocation reads a profile's backend config and returns its state location as sorted
+// key=value pairs, or an empty string when the file is unreadable or does not name a single state
+// (e.g. only the bucket, with the key set elsewhere). A TF_WORKSPACE set for the profile selects a
+// different state in the same backend.
+func backendStateLocation(profile Profile) string {
+	path := profile.BackendConfigPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	settings := utils.ExtractStateLocationFromBackendConfig(string(data))
+	if strings.HasSuffix(path, ".json") {
+		if settings, err = utils.ExtractStateLocationFromJSONBackendConfig(string(data)); err != nil {
+			return ""
+		}
+	}
+	if !utils.IdentifiesState(settings) {
+		return ""
+	}
+	if workspace := profile.Env["TF_WORKSPACE"]; workspace != "" {
+		settings["workspace"] = workspace
+	}
+
+	location := make([]string, 0, len(settings))
+	for key, value := range settings {
+		location = append(location, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(location)
+	return strings.Join(location, ",")
+}
+
+// CheckStateCollisions returns an error naming the profiles whose backend configs point at the
+// same state location, unless they deliberately share a concurrency group
+func CheckStateCollisions(profiles []Profile) error {
+	type owner struct{ name, group string }
+	owners := make(map[string][]owner)
+	var locations []string
+	for _, profile := range profiles {
+		if profile.BackendConfig == "" {
+			continue
+		}
+		location := backendStateLocation(profile)
+		if location == "" {
+			continue
+		}
+		if _, exists := owners[location]; !exists {
+			locations = append(locations, location)
+		}
+		owners[location] = append(owners[location], owner{profile.Name, profile.ConcurrencyGroup})
+	}
+
+	var errs []error
+	for _, location := range locations {
+		sharing := owners[location]
+		if len(sharing) < 2 {
+			continue
+		}
+		names := make([]string, 0, len(sharing))
+		declared := sharing[0].group != ""
+		for _, o := range sharing {
+			names = append(names, o.name)
+			declared = declared && o.group == sharing[0].group
+		}
+		if !declared {
+			errs = append(errs, fmt.Errorf("profiles %s share the state %s", strings.Join(names, ", "), location))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,84 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConcurrencyGroups(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("dev.hcl", "bucket = \"state\"\nkey = \"app.tfstate\"\nregion = \"eu-west-1\"\n")
+	write("dev-eu.hcl", "# same state, other region\nbucket = \"state\"\nkey    = \"app.tfstate\"\nregion = \"eu-central-1\"\n")
+	write("prod.hcl", "bucket = \"state\"\nkey = \"prod.tfstate\"\n")
+
+	profiles := []Profile{
+		{Name: "dev", BackendDir: dir, BackendConfig: "dev.hcl"},
+		{Name: "dev-eu", BackendDir: dir, BackendConfig: "dev-eu.hcl"},
+		{Name: "prod", BackendDir: dir, BackendConfig: "prod.hcl"},
+		{Name: "web", ConcurrencyGroup: "frontend"},
+		{Name: "cdn", ConcurrencyGroup: "frontend"},
+		{Name: "dns", ConcurrencyGroup: "dns"},
+	}
+
+	groups := concurrencyGroups(profiles, ConcurrencyGroupsBackend)
+	if groups["dev"] == "" || groups["dev"] != groups["dev-eu"] {
+		t.Errorf("Expected profiles sharing a state location to share a group, got: %v", groups)
+	}
+	if groups["web"] != "frontend" || groups["cdn"] != "frontend" {
+		t.Errorf("Expected the declared group, got: %v", groups)
+	}
+	if _, exists := groups["prod"]; exists {
+		t.Errorf("Expected a profile with its own state to run without a group, got: %v", groups)
+	}
+	if _, exists := groups["dns"]; exists {
+		t.Errorf("Expected a single-member group to be dropped, got: %v", groups)
+	}
+
+	if groups := concurrencyGroups(profiles, ConcurrencyGroupsManifest); len(groups) != 2 {
+		t.Errorf("Expected only declared groups in manifest mode, got: %v", groups)
+	}
+	if groups := concurrencyGroups(profiles, ConcurrencyGroupsNone); len(groups) != 0 {
+		t.Errorf("Expected no groups in none mode, got: %v", groups)
+	}
+}
+
+func TestCheckStateCollisions(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"dev.tfbackend":  "bucket = \"state\"\nkey = \"app.tfstate\"\n",
+		"test.tfbackend": "bucket = \"state\"\nkey = \"app.tfstate\"\n",
+		"prod.tfbackend": "bucket = \"state\"\nkey = \"prod.tfstate\"\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dev := Profile{Name: "dev", BackendDir: dir, BackendConfig: "dev.tfbackend"}
+	test := Profile{Name: "test", BackendDir: dir, BackendConfig: "test.tfbackend"}
+	prod := Profile{Name: "prod", BackendDir: dir, BackendConfig: "prod.tfbackend"}
+
+	err := CheckStateCollisions([]Profile{dev, test, prod})
+	if err == nil || !strings.Contains(err.Error(), "dev, test") || strings.Contains(err.Error(), "prod") {
+		t.Errorf("Expected dev and test to collide, got: %v", err)
+	}
+
+	// Different terraform workspaces keep separate states in one backend
+	test.Env = map[string]string{"TF_WORKSPACE": "test"}
+	if err := CheckStateCollisions([]Profile{dev, test}); err != nil {
+		t.Errorf("Expected no collision across workspaces, got: %v", err)
+	}
+
+	// A shared concurrency group declares the sharing deliberate
+	dev.ConcurrencyGroup, test.ConcurrencyGroup, test.Env = "app", "app", nil
+	if err := CheckStateCollisions([]Profile{dev, test}); err != nil {
+		t.Errorf("Expected no collision within a concurrency group, got: %v", err)
+	}
+}
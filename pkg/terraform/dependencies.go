@@ -0,0 +1,78 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+)
+
+// executionDependencies returns, for every selected profile, the profiles that must finish first.
+// Apply-style commands run prerequisites first; destroy runs in reverse so dependents are torn
+// down before the infrastructure they rely on. Dependencies outside the selection are ignored.
+func executionDependencies(profiles []Profile, command string) (map[string][]string, error) {
+	selected := make(map[string]bool, len(profiles))
+	for _, profile := range profiles {
+		selected[profile.Name] = true
+	}
+
+	waitsFor := make(map[string][]string, len(profiles))
+	for _, profile := range profiles {
+		for _, dependency := range profile.DependsOn {
+			if !selected[dependency] {
+				continue
+			}
+			if command == "destroy" {
+				waitsFor[dependency] = append(waitsFor[dependency], profile.Name)
+			} else {
+				waitsFor[profile.Name] = append(waitsFor[profile.Name], dependency)
+			}
+		}
+	}
+
+	if cycle := findDependencyCycle(profiles, waitsFor); cycle != nil {
+		return nil, fmt.Errorf("profile dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return waitsFor, nil
+}
+
+// findDependencyCycle returns the profiles forming a cycle in waitsFor, or nil if there is none
+func findDependencyCycle(profiles []Profile, waitsFor map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, entry := range path {
+				if entry == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range waitsFor[name] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, profile := range profiles {
+		if cycle := visit(profile.Name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
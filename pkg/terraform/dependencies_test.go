@@ -0,0 +1,36 @@
+package terraform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExecutionDependencies(t *testing.T) {
+	profiles := []Profile{
+		{Name: "network"},
+		{Name: "app", DependsOn: []string{"network", "unselected"}},
+	}
+
+	waitsFor, err := executionDependencies(profiles, "apply")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(waitsFor, map[string][]string{"app": {"network"}}) {
+		t.Errorf("Expected app to wait for network on apply, got: %v", waitsFor)
+	}
+
+	waitsFor, err = executionDependencies(profiles, "destroy")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(waitsFor, map[string][]string{"network": {"app"}}) {
+		t.Errorf("Expected network to wait for app on destroy, got: %v", waitsFor)
+	}
+
+	profiles[0].DependsOn = []string{"app"}
+	_, err = executionDependencies(profiles, "apply")
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected a dependency cycle error, got: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package terraform
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tapper/pkg/utils"
+	"tapper/pkg/workspace"
+)
+
+// initFingerprint hashes the inputs that determine whether a directory needs terraform init
+func initFingerprint(dir string, profile Profile) (string, error) {
+	hash := sha256.New()
+
+	backendConfigPath := filepath.Join(profile.BackendDir, profile.BackendConfig)
+	backendConfig, err := os.ReadFile(filepath.Join(dir, backendConfigPath))
+	switch {
+	case err == nil && profile.BackendConfig != "":
+		fmt.Fprintf(hash, "%s\n", backendConfigPath)
+		hash.Write(backendConfig)
+	case profile.BackendConfig == "" || os.IsNotExist(err):
+		// Backend configured from the environment
+		fmt.Fprintf(hash, "%s\n", strings.Join(BackendConfigFromEnv(), "\n"))
+	default:
+		return "", fmt.Errorf("error reading backend config: %w", err)
+	}
+
+	lockFile, err := os.ReadFile(filepath.Join(dir, ".terraform.lock.hcl"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("error reading provider lock file: %w", err)
+	}
+	hash.Write(lockFile)
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// initRequired reports whether terraform init must run in dir for the given profile.
+// Init can be skipped when the backend is initialized, providers are installed and
+// neither the backend config nor the provider lock changed since the last init.
+func initRequired(dir string, profile Profile) bool {
+	terraformDir := filepath.Join(dir, ".terraform")
+
+	if exists, err := utils.CheckFileOrDirExists(filepath.Join(terraformDir, "terraform.tfstate")); err != nil || !exists {
+		return true
+	}
+
+	if exists, _ := utils.CheckFileOrDirExists(filepath.Join(dir, ".terraform.lock.hcl")); exists {
+		if installed, err := utils.CheckDirExists(filepath.Join(terraformDir, "providers")); err != nil || !installed {
+			return true
+		}
+	}
+
+	recorded, err := os.ReadFile(filepath.Join(terraformDir, workspace.InitMarkerFile))
+	if err != nil {
+		return true
+	}
+
+	fingerprint, err := initFingerprint(dir, profile)
+	if err != nil {
+		return true
+	}
+	return strings.TrimSpace(string(recorded)) != fingerprint
+}
+
+// recordInit stores the init fingerprint so subsequent runs can skip init
+func recordInit(dir string, profile Profile) error {
+	fingerprint, err := initFingerprint(dir, profile)
+	if err != nil {
+		return err
+	}
+
+	markerPath := filepath.Join(dir, ".terraform", workspace.InitMarkerFile)
+	if err := os.WriteFile(markerPath, []byte(fingerprint+"\n"), 0644); err != nil {
+		return fmt.Errorf("error writing init marker: %w", err)
+	}
+	return nil
+}
+
+// installMarkerFile records the provider lock and configuration of the last backend-less install in the module's .terraform
+const installMarkerFile = ".tapper-install"
+
+// installFingerprint hashes the provider lock file and the module's configuration files, whose
+// required_providers and module blocks determine what is installed
+func installFingerprint(dir string) (string, error) {
+	hash := sha256.New()
+
+	lockFile, err := os.ReadFile(filepath.Join(dir, ".terraform.lock.hcl"))
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("error reading provider lock file: %w", err)
+	}
+	hash.Write(lockFile)
+
+	var configFiles []string
+	for _, pattern := range []string{"*.tf", "*.tf.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return "", err
+		}
+		configFiles = append(configFiles, matches...)
+	}
+	sort.Strings(configFiles)
+	for _, configFile := range configFiles {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading configuration file: %w", err)
+		}
+		fmt.Fprintf(hash, "%s\n", filepath.Base(configFile))
+		hash.Write(content)
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// installRequired reports whether providers and modules must be installed in dir, i.e. nothing
+// was installed yet or the provider lock or configuration changed since the last install
+func installRequired(dir string) bool {
+	if exists, _ := utils.CheckFileOrDirExists(filepath.Join(dir, ".terraform.lock.hcl")); exists {
+		if installed, err := utils.CheckDirExists(filepath.Join(dir, ".terraform", "providers")); err != nil || !installed {
+			return true
+		}
+	}
+
+	recorded, err := os.ReadFile(filepath.Join(dir, ".terraform", installMarkerFile))
+	if err != nil {
+		return true
+	}
+
+	fingerprint, err := installFingerprint(dir)
+	if err != nil {
+		return true
+	}
+	return strings.TrimSpace(string(recorded)) != fingerprint
+}
+
+// recordInstall stores the install fingerprint so subsequent runs can skip the install
+func recordInstall(dir string) error {
+	fingerprint, err := installFingerprint(dir)
+	if err != nil {
+		return err
+	}
+
+	// A module without providers or modules may not have a .terraform directory yet
+	if err := os.MkdirAll(filepath.Join(dir, ".terraform"), 0755); err != nil {
+		return fmt.Errorf("error creating .terraform directory: %w", err)
+	}
+	markerPath := filepath.Join(dir, ".terraform", installMarkerFile)
+	if err := os.WriteFile(markerPath, []byte(fingerprint+"\n"), 0644); err != nil {
+		return fmt.Errorf("error writing install marker: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitRequired(t *testing.T) {
+	dir := t.TempDir()
+	profile := Profile{Name: "dev", BackendConfig: "dev.tfbackend", BackendDir: "backend"}
+
+	os.MkdirAll(filepath.Join(dir, "backend"), 0755)
+	os.WriteFile(filepath.Join(dir, "backend", "dev.tfbackend"), []byte("bucket = \"dev-bucket\""), 0644)
+
+	if !initRequired(dir, profile) {
+		t.Error("Expected init to be required without a .terraform directory")
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".terraform"), 0755)
+	os.WriteFile(filepath.Join(dir, ".terraform", "terraform.tfstate"), []byte("{}"), 0644)
+
+	if !initRequired(dir, profile) {
+		t.Error("Expected init to be required without an init marker")
+	}
+
+	if err := recordInit(dir, profile); err != nil {
+		t.Fatalf("Expected no error recording init, got: %v", err)
+	}
+	if initRequired(dir, profile) {
+		t.Error("Expected init to be skipped after recording it")
+	}
+
+	// A provider lock without installed providers requires init
+	os.WriteFile(filepath.Join(dir, ".terraform.lock.hcl"), []byte("# lock"), 0644)
+	if !initRequired(dir, profile) {
+		t.Error("Expected init to be required when providers are not installed")
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".terraform", "providers"), 0755)
+	recordInit(dir, profile)
+	if initRequired(dir, profile) {
+		t.Error("Expected init to be skipped once providers are installed")
+	}
+
+	// Changing the backend config invalidates the marker
+	os.WriteFile(filepath.Join(dir, "backend", "dev.tfbackend"), []byte("bucket = \"other-bucket\""), 0644)
+	if !initRequired(dir, profile) {
+		t.Error("Expected init to be required after the backend config changed")
+	}
+}
+
+func TestInstallRequired(t *testing.T) {
+	dir := t.TempDir()
+
+	if !installRequired(dir) {
+		t.Error("Expected an install without an install marker")
+	}
+	if err := recordInstall(dir); err != nil {
+		t.Fatalf("Expected no error recording the install, got: %v", err)
+	}
+	if installRequired(dir) {
+		t.Error("Expected the install to be skipped after recording it")
+	}
+
+	// A new provider lock requires installing its providers
+	os.WriteFile(filepath.Join(dir, ".terraform.lock.hcl"), []byte("# lock"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".terraform", "providers"), 0755)
+	if !installRequired(dir) {
+		t.Error("Expected an install after the provider lock changed")
+	}
+
+	// A new module block requires installing the module
+	recordInstall(dir)
+	os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`module "vpc" { source = "./vpc" }`), 0644)
+	if !installRequired(dir) {
+		t.Error("Expected an install after the configuration changed")
+	}
+}
@@ -1,90 +1,474 @@
 package terraform
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+
+	"tapper/pkg/utils"
 )
 
 // InteractionHandler handles user interactions like approval prompts
-type InteractionHandler struct{}
+type InteractionHandler struct {
+	AutoApprove bool // Approve every successfully planned profile without prompting
+	PageSize    int  // Review profiles in pages of this size with one approval per page (0 = one at a time)
+	Pager       bool // Open each profile's output in $PAGER (or less -R) during review
+
+	// Replan plans only the given resources of a profile and returns the new plan file
+	Replan func(profileName string, targets []string) (string, error)
+
+	out io.Writer // Receives results and prompts, os.Stdout when nil
+}
+
+// ReviewDecision is the answer to a review prompt
+type ReviewDecision int
+
+// Review decisions; the "all" decisions also settle every profile still pending review
+const (
+	DecisionReject     ReviewDecision = iota // Reject this profile
+	DecisionApprove                          // Approve this profile
+	DecisionApproveAll                       // Approve this and every remaining successful plan, without batch confirmation
+	DecisionRejectAll                        // Reject this and every remaining profile, and stop reviewing
+)
+
+// appliesToRemaining reports whether the decision also settles the profiles still pending review
+func (d ReviewDecision) appliesToRemaining() bool {
+	return d == DecisionApproveAll || d == DecisionRejectAll
+}
+
+// reviewShortcuts is appended to review prompts to explain the "all" decisions
+const reviewShortcuts = "a = approve all remaining, q = reject all remaining"
 
 // NewInteractionHandler creates a new user interaction handler
 func NewInteractionHandler() *InteractionHandler {
 	return &InteractionHandler{}
 }
 
-// ReviewAndApproveResults displays complete results and handles approval
-func (h *InteractionHandler) ReviewAndApproveResults(results []ExecutionResult) ([]string, error) {
-	var approvedProfiles []string
+// stdout returns the writer receiving results and prompts
+func (h *InteractionHandler) stdout() io.Writer {
+	if h.out == nil {
+		return os.Stdout
+	}
+	return h.out
+}
 
-	for _, result := range results {
-		fmt.Printf("=== Profile: %s ===\n", result.ProfileName)
-		fmt.Printf("Duration: %v\n", result.Duration)
-		fmt.Printf("Working Directory: %s\n", result.WorkingDir)
+// ReviewAndApproveResults displays complete results and handles approval.
+// Profiles already present in plan.ReviewedProfiles are skipped, and persist is
+// called after every decision so an interrupted review can be resumed.
+func (h *InteractionHandler) ReviewAndApproveResults(plan *ExecutionPlan, persist func(*ExecutionPlan) error) ([]string, error) {
+	if err := h.requireInput(); err != nil {
+		return nil, err
+	}
 
-		if result.Error != nil {
-			fmt.Printf("Status: Failed\n")
-			fmt.Printf("Error: %v\n", result.Error)
-		} else if result.Success {
-			fmt.Printf("Status: Success\n")
+	var pending []ExecutionResult
+	for _, result := range plan.Results {
+		if !containsString(plan.ReviewedProfiles, result.ProfileName) {
+			pending = append(pending, result)
 		}
+	}
 
-		if result.Output != "" {
-			fmt.Printf("\nComplete Output:\n%s\n", result.Output)
-		}
+	pageSize := h.PageSize
+	if pageSize <= 0 || h.AutoApprove {
+		pageSize = 1
+	}
+	pages := (len(pending) + pageSize - 1) / pageSize
 
-		approved := h.PromptForApproval(result.ProfileName)
-		if approved {
-			approvedProfiles = append(approvedProfiles, result.ProfileName)
-			fmt.Printf("Approved: %s\n", result.ProfileName)
+	approvedAll := false
+	for start := 0; start < len(pending); start += pageSize {
+		page := pending[start:min(start+pageSize, len(pending))]
+
+		var decision ReviewDecision
+		if len(page) == 1 {
+			result := page[0]
+			h.displayResult(result, h.Pager)
+			decision = h.reviewProfile(plan, result)
+			h.recordReviewDecision(plan, result, decision)
 		} else {
-			fmt.Printf("Rejected: %s\n", result.ProfileName)
+			decision = h.reviewPage(plan, page, start/pageSize+1, pages)
+		}
+
+		// The remaining profiles are decided without showing their output
+		if decision.appliesToRemaining() {
+			for _, result := range pending[start+len(page):] {
+				h.recordReviewDecision(plan, result, decision)
+			}
+		}
+
+		if persist != nil {
+			if err := persist(plan); err != nil {
+				return nil, err
+			}
 		}
 
-		fmt.Println(strings.Repeat("-", 80))
+		fmt.Fprintln(h.stdout(), strings.Repeat("-", 80))
+		if decision.appliesToRemaining() {
+			approvedAll = decision == DecisionApproveAll
+			break
+		}
 	}
 
+	approvedProfiles := plan.ApprovedProfiles
 	if len(approvedProfiles) == 0 {
-		fmt.Println("No profiles approved for execution.")
+		fmt.Fprintln(h.stdout(), "No profiles approved for execution.")
 		return nil, nil
 	}
-	// If there's exactly one profile - don't verify
-	if len(results) == 1 {
+	// If there's exactly one profile or approval is automatic or explicitly for all - don't verify
+	if len(plan.Results) == 1 || h.AutoApprove || approvedAll {
 		return approvedProfiles, nil
 	}
 	return h.ConfirmBatchExecution(approvedProfiles)
 }
 
+// reviewPage displays a page of results and approves or rejects them with a single answer,
+// or falls back to reviewing each profile of the page individually. It returns the decision
+// that ended the page, which may also settle the remaining pages.
+func (h *InteractionHandler) reviewPage(plan *ExecutionPlan, page []ExecutionResult, number, pages int) ReviewDecision {
+	var names, approvable []string
+	for _, result := range page {
+		h.displayResult(result, h.Pager)
+		fmt.Fprintln(h.stdout(), strings.Repeat("-", 80))
+		names = append(names, result.ProfileName)
+		if result.Success {
+			approvable = append(approvable, result.ProfileName)
+		}
+	}
+
+	fmt.Fprintf(h.stdout(), "Page %d/%d: %s\n", number, pages, strings.Join(names, ", "))
+	fmt.Fprintf(h.stdout(), "Approve every successful plan on this page? (y/n/i to review individually, %s): ", reviewShortcuts)
+	response, err := utils.ReadLine()
+	if err != nil {
+		fmt.Fprintf(h.stdout(), "Error reading input: %v, defaulting to 'no'\n", err)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response == "i" || response == "individual" {
+		for i, result := range page {
+			decision := h.reviewProfile(plan, result)
+			h.recordReviewDecision(plan, result, decision)
+			if decision.appliesToRemaining() {
+				for _, rest := range page[i+1:] {
+					h.recordReviewDecision(plan, rest, decision)
+				}
+				return decision
+			}
+		}
+		return DecisionReject
+	}
+
+	decision := parseReviewDecision(response)
+	for _, result := range page {
+		// Profiles whose plan errored are never approved with the page
+		approved := decision != DecisionReject && decision != DecisionRejectAll && containsString(approvable, result.ProfileName)
+		h.recordDecision(plan, result.ProfileName, approved)
+	}
+	return decision
+}
+
+// reviewProfile decides whether a single profile is approved
+func (h *InteractionHandler) reviewProfile(plan *ExecutionPlan, result ExecutionResult) ReviewDecision {
+	switch {
+	case h.AutoApprove:
+		// Profiles whose plan errored are never approved automatically
+		if result.Success {
+			return DecisionApprove
+		}
+		return DecisionReject
+	case result.Success && result.HasChanges && result.PlanFile != "":
+		return h.promptForResourceApproval(plan, result)
+	default:
+		return h.PromptForApproval(result.ProfileName)
+	}
+}
+
+// recordReviewDecision records a review decision for a profile. Approving all remaining
+// profiles never approves one whose plan errored.
+func (h *InteractionHandler) recordReviewDecision(plan *ExecutionPlan, result ExecutionResult, decision ReviewDecision) {
+	approved := decision == DecisionApprove || (decision == DecisionApproveAll && result.Success)
+	h.recordDecision(plan, result.ProfileName, approved)
+}
+
+// recordDecision marks a profile as reviewed and records whether it was approved
+func (h *InteractionHandler) recordDecision(plan *ExecutionPlan, profileName string, approved bool) {
+	if approved {
+		plan.ApprovedProfiles = append(plan.ApprovedProfiles, profileName)
+		fmt.Fprintf(h.stdout(), "Approved: %s\n", profileName)
+	} else {
+		fmt.Fprintf(h.stdout(), "Rejected: %s\n", profileName)
+	}
+	plan.ReviewedProfiles = append(plan.ReviewedProfiles, profileName)
+}
+
+// ReviewSummary shows only each profile's status and change counts, then asks for a single
+// approval covering every successfully planned profile that has not been reviewed yet.
+func (h *InteractionHandler) ReviewSummary(plan *ExecutionPlan, persist func(*ExecutionPlan) error) ([]string, error) {
+	if err := h.requireInput(); err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	fmt.Fprintf(h.stdout(), "%-24s %-16s %8s %8s %8s\n", "PROFILE", "STATUS", "ADD", "CHANGE", "DESTROY")
+	for _, result := range plan.Results {
+		if containsString(plan.ReviewedProfiles, result.ProfileName) {
+			continue
+		}
+
+		add, change, destroy := "-", "-", "-"
+		if result.Success {
+			candidates = append(candidates, result.ProfileName)
+			if counts, found := resultPlanCounts(result); found {
+				add, change, destroy = fmt.Sprint(counts.Add), fmt.Sprint(counts.Change), fmt.Sprint(counts.Destroy)
+			}
+		}
+		fmt.Fprintf(h.stdout(), "%-24s %-16s %8s %8s %8s\n", result.ProfileName, reviewStatus(result), add, change, destroy)
+	}
+	fmt.Fprintln(h.stdout())
+
+	if len(candidates) > 0 {
+		if h.AutoApprove {
+			fmt.Fprintf(h.stdout(), "Auto-approving %d profile(s): %s\n", len(candidates), strings.Join(candidates, ", "))
+			plan.ApprovedProfiles = append(plan.ApprovedProfiles, candidates...)
+		} else {
+			fmt.Fprintf(h.stdout(), "Approve execution for %d profile(s): %s? (y/n): ", len(candidates), strings.Join(candidates, ", "))
+			if h.getYesNoResponse() {
+				plan.ApprovedProfiles = append(plan.ApprovedProfiles, candidates...)
+			}
+		}
+	}
+
+	for _, result := range plan.Results {
+		if !containsString(plan.ReviewedProfiles, result.ProfileName) {
+			plan.ReviewedProfiles = append(plan.ReviewedProfiles, result.ProfileName)
+		}
+	}
+	if persist != nil {
+		if err := persist(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(plan.ApprovedProfiles) == 0 {
+		fmt.Fprintln(h.stdout(), "No profiles approved for execution.")
+		return nil, nil
+	}
+	return plan.ApprovedProfiles, nil
+}
+
+// DisplayResults prints the complete results of every profile without prompting
+func (h *InteractionHandler) DisplayResults(results []ExecutionResult) {
+	for _, result := range results {
+		h.DisplayResult(result)
+		fmt.Fprintln(h.stdout(), strings.Repeat("-", 80))
+	}
+}
+
+// DisplayResult prints the status and complete output of a single profile
+func (h *InteractionHandler) DisplayResult(result ExecutionResult) {
+	h.displayResult(result, false)
+}
+
+// displayResult prints a profile's result, opening its output in the pager if page is set.
+// Only the review pages, so output printed while profiles run never blocks on a pager.
+func (h *InteractionHandler) displayResult(result ExecutionResult, page bool) {
+	fmt.Fprintf(h.stdout(), "=== Profile: %s ===\n", result.ProfileName)
+	fmt.Fprintf(h.stdout(), "Duration: %v\n", result.Duration)
+	fmt.Fprintf(h.stdout(), "Working Directory: %s\n", result.WorkingDir)
+
+	fmt.Fprintf(h.stdout(), "Status: %s\n", reviewStatus(result))
+	if result.Error != nil {
+		fmt.Fprintf(h.stdout(), "Error: %v\n", result.Error)
+	}
+
+	if result.Changes != nil {
+		fmt.Fprintf(h.stdout(), "Changes: %s\n", result.Changes)
+	}
+
+	if result.Output != "" {
+		if page && !h.AutoApprove && h.pageOutput(result) {
+			return
+		}
+		fmt.Fprintf(h.stdout(), "\nComplete Output:\n%s\n", result.Output)
+	}
+}
+
+// pageOutput shows a profile's output in the pager, reporting false when it
+// should be printed inline instead because no pager is available
+func (h *InteractionHandler) pageOutput(result ExecutionResult) bool {
+	content := fmt.Sprintf("=== Profile: %s ===\n\n%s\n", result.ProfileName, result.Output)
+	if err := utils.Page(content); err != nil {
+		if !errors.Is(err, utils.ErrNoPager) {
+			fmt.Fprintf(h.stdout(), "Error running pager: %v\n", err)
+		}
+		return false
+	}
+	fmt.Fprintln(h.stdout(), "(output shown in pager)")
+	return true
+}
+
+// reviewStatus labels a planned profile from terraform's detailed exit code:
+// 0 is "no changes", 2 is "changes pending" and anything else "errored"
+func reviewStatus(result ExecutionResult) string {
+	switch {
+	case result.Error != nil || !result.Success:
+		return "errored"
+	case result.ChangesKnown && result.HasChanges:
+		return "changes pending"
+	case result.ChangesKnown:
+		return "no changes"
+	default:
+		return "succeeded"
+	}
+}
+
 // PromptForApproval prompts the user for approval of a specific profile
-func (h *InteractionHandler) PromptForApproval(profileName string) bool {
-	fmt.Printf("Approve execution for profile '%s'? (y/n): ", profileName)
-	return h.getYesNoResponse()
+func (h *InteractionHandler) PromptForApproval(profileName string) ReviewDecision {
+	fmt.Fprintf(h.stdout(), "Approve execution for profile '%s'? (y/n, %s): ", profileName, reviewShortcuts)
+	response, err := utils.ReadLine()
+	if err != nil {
+		fmt.Fprintf(h.stdout(), "Error reading input: %v, defaulting to 'no'\n", err)
+		return DecisionReject
+	}
+	return parseReviewDecision(response)
+}
+
+// promptForResourceApproval prompts for a profile with a saved plan, offering to approve only
+// a subset of its resource changes. The subset is re-planned with -target and shown for approval,
+// and that plan replaces the profile's plan.
+func (h *InteractionHandler) promptForResourceApproval(plan *ExecutionPlan, result ExecutionResult) ReviewDecision {
+	fmt.Fprintf(h.stdout(), "Approve execution for profile '%s'? (y/n/s to select resources, %s): ", result.ProfileName, reviewShortcuts)
+	response, err := utils.ReadLine()
+	if err != nil {
+		fmt.Fprintf(h.stdout(), "Error reading input: %v, defaulting to 'no'\n", err)
+		return DecisionReject
+	}
+
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "s", "select":
+		targets, err := h.selectResources(result)
+		if err != nil {
+			fmt.Fprintf(h.stdout(), "Error selecting resources: %v, defaulting to 'no'\n", err)
+			return DecisionReject
+		}
+		return h.approveTargetedPlan(plan, result.ProfileName, targets)
+	default:
+		return parseReviewDecision(response)
+	}
+}
+
+// approveTargetedPlan re-plans the selected resources of a profile and, once the new plan is
+// approved, makes it the plan applied for the profile
+func (h *InteractionHandler) approveTargetedPlan(plan *ExecutionPlan, profileName string, targets []string) ReviewDecision {
+	if h.Replan == nil {
+		fmt.Fprintln(h.stdout(), "Resource selection is not available, defaulting to 'no'")
+		return DecisionReject
+	}
+
+	fmt.Fprintf(h.stdout(), "Planning %d selected resource(s) for %s...\n", len(targets), profileName)
+	planFile, err := h.Replan(profileName, targets)
+	if err != nil {
+		fmt.Fprintf(h.stdout(), "Error planning selected resources: %v, defaulting to 'no'\n", err)
+		return DecisionReject
+	}
+
+	fmt.Fprintf(h.stdout(), "Apply this targeted plan for profile '%s'? (y/n): ", profileName)
+	if !h.getYesNoResponse() {
+		return DecisionReject
+	}
+	for i := range plan.Results {
+		if plan.Results[i].ProfileName == profileName {
+			plan.Results[i].PlanFile = planFile
+		}
+	}
+	return DecisionApprove
+}
+
+// selectResources lets the user pick resource changes from a profile's saved plan
+func (h *InteractionHandler) selectResources(result ExecutionResult) ([]string, error) {
+	changes, err := PlanResourceChanges(result.WorkingDir, result.PlanFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no resource changes found in the plan")
+	}
+
+	labels := make([]string, len(changes))
+	addresses := make(map[string]string, len(changes))
+	for i, change := range changes {
+		labels[i] = change.String()
+		addresses[labels[i]] = change.Address
+	}
+
+	config := utils.DefaultMultiSelectConfig(
+		"Select resources (use Tab to select multiple): ",
+		fmt.Sprintf("Resource changes for %s - Tab to select, Enter to confirm", result.ProfileName),
+	)
+	selected, err := utils.InteractiveSelect(labels, config)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]string, len(selected))
+	for i, label := range selected {
+		targets[i] = addresses[label]
+	}
+	return targets, nil
 }
 
 // ConfirmBatchExecution confirms execution of multiple approved profiles
 func (h *InteractionHandler) ConfirmBatchExecution(approvedProfiles []string) ([]string, error) {
-	fmt.Printf("\nApproved profiles: %s\n", strings.Join(approvedProfiles, ", "))
-	fmt.Print("Proceed with execution? (y/n): ")
+	fmt.Fprintf(h.stdout(), "\nApproved profiles: %s\n", strings.Join(approvedProfiles, ", "))
+	fmt.Fprint(h.stdout(), "Proceed with execution? (y/n): ")
 
 	if h.getYesNoResponse() {
 		return approvedProfiles, nil
 	}
 
-	fmt.Println("Execution cancelled.")
+	fmt.Fprintln(h.stdout(), "Execution cancelled.")
 	return nil, nil
 }
 
+// requireInput fails fast when approval prompts cannot be answered, instead of blocking forever
+func (h *InteractionHandler) requireInput() error {
+	if h.AutoApprove || utils.IsInteractive() {
+		return nil
+	}
+	return fmt.Errorf("cannot prompt for approval: %w; pass --auto-approve to run non-interactively", utils.ErrNonInteractive)
+}
+
 // getYesNoResponse gets a yes/no response from the user
 func (h *InteractionHandler) getYesNoResponse() bool {
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
+	response, err := utils.ReadLine()
 	if err != nil {
-		fmt.Printf("Error reading input: %v, defaulting to 'no'\n", err)
+		fmt.Fprintf(h.stdout(), "Error reading input: %v, defaulting to 'no'\n", err)
 		return false
 	}
 
 	response = strings.TrimSpace(strings.ToLower(response))
 	return response == "y" || response == "yes"
 }
+
+// parseReviewDecision maps an answer to a review prompt to a decision; anything unknown rejects
+func parseReviewDecision(response string) ReviewDecision {
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "y", "yes":
+		return DecisionApprove
+	case "a", "all":
+		return DecisionApproveAll
+	case "q", "quit", "n!":
+		return DecisionRejectAll
+	default:
+		return DecisionReject
+	}
+}
+
+// containsString reports whether value is present in values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
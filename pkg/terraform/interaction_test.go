@@ -0,0 +1,63 @@
+package terraform
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReviewAndApproveResultsAutoApprove(t *testing.T) {
+	handler := &InteractionHandler{AutoApprove: true}
+	plan := &ExecutionPlan{
+		Results: []ExecutionResult{
+			{ProfileName: "dev", Success: true},
+			{ProfileName: "prod", Success: false, Error: errors.New("plan failed")},
+			{ProfileName: "staging", Success: true},
+		},
+	}
+
+	approved, err := handler.ReviewAndApproveResults(plan, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(approved) != 2 || approved[0] != "dev" || approved[1] != "staging" {
+		t.Errorf("Expected only successful plans to be approved, got: %v", approved)
+	}
+}
+
+func TestReviewDecisions(t *testing.T) {
+	for response, want := range map[string]ReviewDecision{
+		"y\n": DecisionApprove, "YES": DecisionApprove, "n": DecisionReject, "": DecisionReject,
+		"a": DecisionApproveAll, "q": DecisionRejectAll, "n!": DecisionRejectAll,
+	} {
+		if got := parseReviewDecision(response); got != want {
+			t.Errorf("parseReviewDecision(%q) = %v, want %v", response, got, want)
+		}
+	}
+
+	// Approving all remaining profiles skips those whose plan errored
+	handler := &InteractionHandler{}
+	plan := &ExecutionPlan{}
+	handler.recordReviewDecision(plan, ExecutionResult{ProfileName: "dev", Success: true}, DecisionApproveAll)
+	handler.recordReviewDecision(plan, ExecutionResult{ProfileName: "prod"}, DecisionApproveAll)
+	if len(plan.ApprovedProfiles) != 1 || plan.ApprovedProfiles[0] != "dev" || len(plan.ReviewedProfiles) != 2 {
+		t.Errorf("Expected only dev approved and both reviewed, got: %v, %v", plan.ApprovedProfiles, plan.ReviewedProfiles)
+	}
+}
+
+func TestReviewStatus(t *testing.T) {
+	tests := []struct {
+		result ExecutionResult
+		want   string
+	}{
+		{ExecutionResult{Success: true, ChangesKnown: true}, "no changes"},
+		{ExecutionResult{Success: true, ChangesKnown: true, HasChanges: true, ExitCode: 2}, "changes pending"},
+		{ExecutionResult{ExitCode: 1, Error: errors.New("exit status 1")}, "errored"},
+		{ExecutionResult{Success: true}, "succeeded"},
+	}
+
+	for _, test := range tests {
+		if got := reviewStatus(test.result); got != test.want {
+			t.Errorf("Expected %q for %+v, got %q", test.want, test.result, got)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package terraform
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// StateLockError is the message terraform prints when the state lock is held elsewhere
+	StateLockError = "Error acquiring the state lock"
+)
+
+// IsStateLockError checks if the given error output indicates the state is locked
+func IsStateLockError(output string) bool {
+	return strings.Contains(output, StateLockError)
+}
+
+// LockArgs builds terraform's -lock and -lock-timeout arguments. lock is nil when
+// locking was not set explicitly; a zero timeout leaves terraform's default.
+func LockArgs(lock *bool, timeout time.Duration) ([]string, error) {
+	if timeout < 0 {
+		return nil, fmt.Errorf("lock timeout must not be negative, got %s", timeout)
+	}
+	if timeout > 0 && lock != nil && !*lock {
+		return nil, fmt.Errorf("a lock timeout has no effect with locking disabled (-lock=false)")
+	}
+
+	var args []string
+	if lock != nil {
+		args = append(args, fmt.Sprintf("-lock=%t", *lock))
+	}
+	if timeout > 0 {
+		args = append(args, fmt.Sprintf("-lock-timeout=%s", timeout))
+	}
+	return args, nil
+}
+
+// StateLockHolder extracts who holds the state lock and the lock ID from terraform's Lock Info block
+func StateLockHolder(output string) (who string, id string) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		// Lock info lines are indented and may be prefixed with the diagnostic gutter ("│")
+		line := strings.TrimSpace(strings.TrimLeft(scanner.Text(), "│ \t"))
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "Who":
+			who = strings.TrimSpace(value)
+		case "ID":
+			id = strings.TrimSpace(value)
+		}
+	}
+	return who, id
+}
+
+// StateLockedError reports a command that failed because another run holds the state lock
+type StateLockedError struct {
+	Who string // Lock holder from terraform's Lock Info, empty when unknown
+	ID  string // Lock ID to pass to force-unlock, empty when unknown
+	Err error
+}
+
+func (e *StateLockedError) Error() string {
+	return fmt.Sprintf("%s: %v", lockDescription(e.Who, e.ID), e.Err)
+}
+
+func (e *StateLockedError) Unwrap() error {
+	return e.Err
+}
+
+// Guidance explains how to proceed once the lock holder is known
+func (e *StateLockedError) Guidance() string {
+	id := e.ID
+	if id == "" {
+		id = "<lock ID>"
+	}
+	return fmt.Sprintf("Wait for the other run to finish. If it is no longer running, release the lock with "+
+		"'tapper unlock <profile> %s' (terraform force-unlock), or retry with -lock=false "+
+		"(unsafe: only when nothing else can write this state).", id)
+}
+
+// newStateLockedError parses the lock holder from terraform's output
+func newStateLockedError(output string, err error) *StateLockedError {
+	who, id := StateLockHolder(output)
+	return &StateLockedError{Who: who, ID: id, Err: err}
+}
+
+// stateLockMessage describes a lock conflict in a way that points at the lock holder
+func stateLockMessage(output string) string {
+	return lockDescription(StateLockHolder(output))
+}
+
+// lockDescription names the lock holder and lock ID, when known
+func lockDescription(who, id string) string {
+	if who == "" {
+		who = "another process"
+	}
+
+	message := "state is locked by " + who
+	if id != "" {
+		message += " (lock ID " + id + ")"
+	}
+	return message
+}
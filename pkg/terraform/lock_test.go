@@ -0,0 +1,76 @@
+package terraform
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStateLockError(t *testing.T) {
+	output := `
+│ Error: Error acquiring the state lock
+│
+│ Error message: ConditionalCheckFailedException: The conditional request failed
+│ Lock Info:
+│   ID:        0f8e3c1a-5b2d-4c6e-9a7f-123456789abc
+│   Path:      my-state/dev/terraform.tfstate
+│   Operation: OperationTypeApply
+│   Who:       alice@build-host
+│   Version:   1.9.5
+│   Created:   2024-01-01 10:00:00.000000000 +0000 UTC
+│   Info:
+`
+	if !IsStateLockError(output) {
+		t.Fatal("Expected output to be detected as a state lock error")
+	}
+	if IsStateLockError("Error: Invalid reference") {
+		t.Error("Expected unrelated error not to be detected as a state lock error")
+	}
+
+	who, id := StateLockHolder(output)
+	if who != "alice@build-host" || id != "0f8e3c1a-5b2d-4c6e-9a7f-123456789abc" {
+		t.Errorf("Unexpected lock holder: who=%q id=%q", who, id)
+	}
+
+	expected := "state is locked by alice@build-host (lock ID 0f8e3c1a-5b2d-4c6e-9a7f-123456789abc)"
+	if message := stateLockMessage(output); message != expected {
+		t.Errorf("Expected %q, got %q", expected, message)
+	}
+}
+
+func TestStateLockedError(t *testing.T) {
+	output := "Error: Error acquiring the state lock\n  ID:        abc-123\n  Who:       bob@ci\n"
+	err := newStateLockedError(output, errors.New("exit status 1"))
+
+	var lockErr *StateLockedError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &lockErr) {
+		t.Fatal("Expected a recognizable StateLockedError")
+	}
+	if lockErr.Error() != "state is locked by bob@ci (lock ID abc-123): exit status 1" {
+		t.Errorf("Unexpected error message: %s", lockErr.Error())
+	}
+	if guidance := lockErr.Guidance(); !strings.Contains(guidance, "tapper unlock <profile> abc-123") || !strings.Contains(guidance, "-lock=false") {
+		t.Errorf("Expected unlock and -lock=false guidance, got: %s", guidance)
+	}
+}
+
+func TestLockArgs(t *testing.T) {
+	enabled, disabled := true, false
+
+	args, err := LockArgs(&enabled, 2*time.Minute)
+	if err != nil || !reflect.DeepEqual(args, []string{"-lock=true", "-lock-timeout=2m0s"}) {
+		t.Errorf("LockArgs(true, 2m) = %v, %v", args, err)
+	}
+	if args, err := LockArgs(nil, 0); err != nil || len(args) != 0 {
+		t.Errorf("LockArgs(nil, 0) = %v, %v, want no args", args, err)
+	}
+	if _, err := LockArgs(&disabled, time.Minute); err == nil {
+		t.Error("expected an error combining a lock timeout with -lock=false")
+	}
+	if _, err := LockArgs(nil, -time.Second); err == nil {
+		t.Error("expected an error for a negative lock timeout")
+	}
+}
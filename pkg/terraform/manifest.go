@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ManifestFile is the optional project-level tapper configuration file
+const ManifestFile = "tapper.json"
+
+// Default directories holding the backend configs and var files, relative to the module
+const (
+	DefaultBackendDir = "backend"
+	DefaultVarsDir    = "vars"
+)
+
+// Manifest represents the project-level tapper configuration
+type Manifest struct {
+	BackendDir      string                     `json:"backend_dir,omitempty"`
+	VarsDir         string                     `json:"vars_dir,omitempty"`
+	IsolateDirs     []string                   `json:"isolate_dirs,omitempty"`     // Mirrored per file in each workspace
+	WorkspaceIgnore []string                   `json:"workspace_ignore,omitempty"` // Left out of workspaces, on top of the defaults
+	Recursive       bool                       `json:"recursive,omitempty"`        // Also detect profiles of modules in subdirectories
+	Profiles        map[string]ProfileSettings `json:"profiles"`
+	Groups          map[string][]string        `json:"groups,omitempty"`
+	Aliases         map[string]string          `json:"aliases,omitempty"` // Short names for profiles, e.g. "prod"
+}
+
+// ProfileSettings holds per-profile terraform defaults, overridable by explicit flags
+type ProfileSettings struct {
+	Lock             *bool             `json:"lock,omitempty"`
+	Parallelism      int               `json:"parallelism,omitempty"`
+	DependsOn        []string          `json:"depends_on,omitempty"`
+	ConcurrencyGroup string            `json:"concurrency_group,omitempty"` // Profiles in the same group run one at a time
+	Env              map[string]string `json:"env,omitempty"`               // Overrides the profile's .env file
+}
+
+// LoadManifest reads the manifest from path, returning an empty manifest when it does not exist
+func LoadManifest(path string) (*Manifest, error) {
+	manifest := &Manifest{Profiles: map[string]ProfileSettings{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// Dirs returns the backend and vars directories, falling back to the defaults
+func (m *Manifest) Dirs() (backendDir, varsDir string) {
+	backendDir, varsDir = DefaultBackendDir, DefaultVarsDir
+	if m.BackendDir != "" {
+		backendDir = filepath.Clean(m.BackendDir)
+	}
+	if m.VarsDir != "" {
+		varsDir = filepath.Clean(m.VarsDir)
+	}
+	return backendDir, varsDir
+}
+
+// applyManifest copies the manifest's per-profile settings onto the detected profiles
+func applyManifest(manifest *Manifest, profiles []Profile) {
+	for i := range profiles {
+		settings, exists := manifest.Profiles[profiles[i].Name]
+		if !exists {
+			continue
+		}
+		profiles[i].Lock = settings.Lock
+		profiles[i].Parallelism = settings.Parallelism
+		profiles[i].DependsOn = settings.DependsOn
+		profiles[i].ConcurrencyGroup = settings.ConcurrencyGroup
+		for name, value := range settings.Env {
+			if profiles[i].Env == nil {
+				profiles[i].Env = make(map[string]string)
+			}
+			profiles[i].Env[name] = value
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// MarkdownDiffLimit is the maximum number of bytes of plan output rendered per profile,
+// keeping the whole comment well below GitHub's 65536 character limit
+const MarkdownDiffLimit = 16000
+
+// diffMarkerPattern matches terraform's indented change markers, e.g. "  + resource"
+var diffMarkerPattern = regexp.MustCompile(`(?m)^([ \t]*)([-+~])`)
+
+// RenderMarkdown writes plan results as a markdown PR comment: a table of change counts
+// followed by a collapsible section with the plan output of every profile
+func RenderMarkdown(w io.Writer, plan *ExecutionPlan) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## tapper %s\n\n", plan.Command)
+	if plan.Ticket != "" {
+		fmt.Fprintf(&b, "**Ticket:** %s\n\n", plan.Ticket)
+	}
+	if plan.Reason != "" {
+		fmt.Fprintf(&b, "**Reason:** %s\n\n", plan.Reason)
+	}
+
+	b.WriteString("| Profile | Status | Add | Change | Destroy |\n")
+	b.WriteString("|---|---|---:|---:|---:|\n")
+	for _, result := range plan.Results {
+		add, change, destroy := "-", "-", "-"
+		if counts, found := resultPlanCounts(result); found && result.Success {
+			add, change, destroy = fmt.Sprint(counts.Add), fmt.Sprint(counts.Change), fmt.Sprint(counts.Destroy)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", result.ProfileName, markdownStatus(result), add, change, destroy)
+	}
+
+	for _, result := range plan.Results {
+		fmt.Fprintf(&b, "\n<details><summary><b>%s</b>: %s</summary>\n\n", result.ProfileName, markdownStatus(result))
+		b.WriteString("```diff\n")
+		b.WriteString(markdownDiff(result.Output))
+		b.WriteString("```\n")
+		if len(result.Output) > MarkdownDiffLimit {
+			fmt.Fprintf(&b, "\n_Output truncated to the first %d bytes; see the CI logs for the full plan._\n", MarkdownDiffLimit)
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("error writing markdown: %w", err)
+	}
+	return nil
+}
+
+// markdownStatus describes a result's outcome for the markdown table
+func markdownStatus(result ExecutionResult) string {
+	switch {
+	case !result.Success:
+		return "❌ failed"
+	case result.ChangesKnown && result.HasChanges:
+		return "📝 changes"
+	case result.ChangesKnown:
+		return "✅ no changes"
+	default:
+		return "✅ ok"
+	}
+}
+
+// markdownDiff truncates plan output and moves change markers to the start of the line so
+// GitHub's diff highlighting applies
+func markdownDiff(output string) string {
+	if len(output) > MarkdownDiffLimit {
+		output = output[:MarkdownDiffLimit]
+	}
+	output = strings.ReplaceAll(output, "```", "` ` `")
+	output = diffMarkerPattern.ReplaceAllString(output, "$2$1")
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	return output
+}
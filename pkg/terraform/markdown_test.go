@@ -0,0 +1,40 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	plan := &ExecutionPlan{
+		Command: "plan",
+		Results: []ExecutionResult{
+			{
+				ProfileName:  "dev",
+				Success:      true,
+				ChangesKnown: true,
+				HasChanges:   true,
+				Output:       "  + resource \"aws_s3_bucket\" \"logs\" {\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n",
+			},
+			{ProfileName: "prod", Output: strings.Repeat("x", MarkdownDiffLimit+10)},
+		},
+	}
+
+	var b strings.Builder
+	if err := RenderMarkdown(&b, plan); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	markdown := b.String()
+
+	for _, expected := range []string{
+		"| dev | 📝 changes | 1 | 0 | 0 |",
+		"| prod | ❌ failed | - | - | - |",
+		"<details><summary><b>dev</b>",
+		"+   resource \"aws_s3_bucket\" \"logs\" {",
+		"Output truncated",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", expected, markdown)
+		}
+	}
+}
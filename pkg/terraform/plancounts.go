@@ -0,0 +1,46 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// planCountsPattern matches terraform's plan summary line, e.g. "Plan: 1 to add, 2 to change, 0 to destroy."
+var planCountsPattern = regexp.MustCompile(`(\d+) to add, (\d+) to change, (\d+) to destroy`)
+
+// PlanCounts holds the number of resource actions in a plan
+type PlanCounts struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+	Drift   int `json:"drift,omitempty"` // Resources changed outside of terraform
+}
+
+// String formats the counts compactly, e.g. "+3 ~1 -0"
+func (c PlanCounts) String() string {
+	return fmt.Sprintf("+%d ~%d -%d", c.Add, c.Change, c.Destroy)
+}
+
+// ParsePlanCounts extracts the resource change counts from plan output.
+// It reports false when the output contains neither a plan summary nor a no-changes message.
+func ParsePlanCounts(output string) (PlanCounts, bool) {
+	match := planCountsPattern.FindStringSubmatch(output)
+	if match == nil {
+		return PlanCounts{}, strings.Contains(output, "No changes.")
+	}
+
+	add, _ := strconv.Atoi(match[1])
+	change, _ := strconv.Atoi(match[2])
+	destroy, _ := strconv.Atoi(match[3])
+	return PlanCounts{Add: add, Change: change, Destroy: destroy}, true
+}
+
+// resultPlanCounts returns a result's change counts, tallied from its JSON plan or parsed from the plan text
+func resultPlanCounts(result ExecutionResult) (PlanCounts, bool) {
+	if result.Changes != nil {
+		return *result.Changes, true
+	}
+	return ParsePlanCounts(result.Output)
+}
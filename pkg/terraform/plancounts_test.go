@@ -0,0 +1,51 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePlanCounts(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected PlanCounts
+		found    bool
+	}{
+		{"Plan: 1 to add, 2 to change, 3 to destroy.", PlanCounts{Add: 1, Change: 2, Destroy: 3}, true},
+		{"Plan: 1 to import, 0 to add, 4 to change, 0 to destroy.", PlanCounts{Change: 4}, true},
+		{"No changes. Your infrastructure matches the configuration.", PlanCounts{}, true},
+		{"Error: Invalid reference", PlanCounts{}, false},
+	}
+
+	for _, test := range tests {
+		counts, found := ParsePlanCounts(test.output)
+		if counts != test.expected || found != test.found {
+			t.Errorf("ParsePlanCounts(%q) = %+v, %t; expected %+v, %t", test.output, counts, found, test.expected, test.found)
+		}
+	}
+}
+
+func TestParsePlanJSONCounts(t *testing.T) {
+	data := []byte(`{
+  "resource_drift": [{"address": "aws_s3_bucket.logs", "change": {"actions": ["update"]}}],
+  "resource_changes": [
+    {"address": "aws_instance.web", "change": {"actions": ["create"]}},
+    {"address": "aws_instance.db", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_iam_role.app", "change": {"actions": ["update"]}},
+    {"address": "aws_vpc.main", "change": {"actions": ["no-op"]}}
+  ]
+}`)
+
+	counts, err := ParsePlanJSONCounts(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := PlanCounts{Add: 2, Change: 1, Destroy: 1, Drift: 1}
+	if counts != expected || counts.String() != "+2 ~1 -1" {
+		t.Errorf("Expected %+v, got %+v (%s)", expected, counts, counts)
+	}
+
+	if _, err := ParsePlanJSONCounts([]byte("Error: unexpected")); err == nil || !strings.Contains(err.Error(), "error parsing plan JSON") {
+		t.Errorf("Expected parse error, got: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ResourceChange is a single resource action from a plan's JSON representation
+type ResourceChange struct {
+	Address string
+	Actions []string
+}
+
+// String formats the change as "address (action, action)"
+func (rc ResourceChange) String() string {
+	return fmt.Sprintf("%s (%s)", rc.Address, strings.Join(rc.Actions, ", "))
+}
+
+// planJSON is the subset of terraform's JSON plan format used by tapper
+type planJSON struct {
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Change  struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+	ResourceDrift []json.RawMessage `json:"resource_drift"`
+}
+
+// ParsePlanJSON returns the resource changes of a plan rendered by terraform show -json,
+// leaving out resources that are unchanged or only read
+func ParsePlanJSON(data []byte) ([]ResourceChange, error) {
+	var plan planJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("error parsing plan JSON: %w", err)
+	}
+
+	var changes []ResourceChange
+	for _, rc := range plan.ResourceChanges {
+		actions := rc.Change.Actions
+		if len(actions) == 0 || (len(actions) == 1 && (actions[0] == "no-op" || actions[0] == "read")) {
+			continue
+		}
+		changes = append(changes, ResourceChange{Address: rc.Address, Actions: actions})
+	}
+	return changes, nil
+}
+
+// ParsePlanJSONCounts tallies the resource changes and drift of a plan rendered by terraform show -json
+func ParsePlanJSONCounts(data []byte) (PlanCounts, error) {
+	changes, err := ParsePlanJSON(data)
+	if err != nil {
+		return PlanCounts{}, err
+	}
+
+	var counts PlanCounts
+	for _, change := range changes {
+		for _, action := range change.Actions {
+			switch action {
+			case "create":
+				counts.Add++
+			case "update":
+				counts.Change++
+			case "delete":
+				counts.Destroy++
+			}
+		}
+	}
+
+	var plan planJSON
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return PlanCounts{}, fmt.Errorf("error parsing plan JSON: %w", err)
+	}
+	counts.Drift = len(plan.ResourceDrift)
+	return counts, nil
+}
+
+// PlanResourceChanges renders a saved plan file as JSON and returns its resource changes
+func PlanResourceChanges(workspacePath, planFile string) ([]ResourceChange, error) {
+	cmd := exec.Command(Binary, "show", "-json", planFile)
+	cmd.Dir = workspacePath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running terraform show: %w", err)
+	}
+	return ParsePlanJSON(output)
+}
@@ -0,0 +1,30 @@
+package terraform
+
+import "testing"
+
+func TestParsePlanJSON(t *testing.T) {
+	data := []byte(`{
+		"format_version": "1.2",
+		"resource_changes": [
+			{"address": "aws_s3_bucket.logs", "change": {"actions": ["create"]}},
+			{"address": "aws_iam_role.app", "change": {"actions": ["no-op"]}},
+			{"address": "data.aws_caller_identity.current", "change": {"actions": ["read"]}},
+			{"address": "aws_instance.web[0]", "change": {"actions": ["delete", "create"]}}
+		]
+	}`)
+
+	changes, err := ParsePlanJSON(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 resource changes, got %d: %v", len(changes), changes)
+	}
+	if changes[0].Address != "aws_s3_bucket.logs" || changes[1].String() != "aws_instance.web[0] (delete, create)" {
+		t.Errorf("Unexpected resource changes: %v", changes)
+	}
+
+	if _, err := ParsePlanJSON([]byte("not json")); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
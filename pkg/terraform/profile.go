@@ -1,49 +1,127 @@
 package terraform
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"tapper/pkg/utils"
 )
 
 // Profile represents a Terraform configuration profile
 type Profile struct {
-	Name          string `json:"name"`
-	BackendConfig string `json:"backendconfig"`
-	VarFile       string `json:"varfile"`
-	BackendDir    string `json:"backenddir"`
-	VarsDir       string `json:"varsdir"`
-	LastUsed      string `json:"lastused"`
+	Name             string            `json:"name"`
+	BackendConfig    string            `json:"backendconfig"`
+	VarFile          string            `json:"varfile"`
+	BackendDir       string            `json:"backenddir"`
+	VarsDir          string            `json:"varsdir"`
+	LastUsed         string            `json:"lastused"`
+	Lock             *bool             `json:"lock,omitempty"`
+	Parallelism      int               `json:"parallelism,omitempty"`
+	DependsOn        []string          `json:"depends_on,omitempty"`
+	ModuleDir        string            `json:"moduledir,omitempty"`         // Module directory of a recursively discovered profile
+	ConcurrencyGroup string            `json:"concurrency_group,omitempty"` // Profiles in the same group run one at a time
+	Env              map[string]string `json:"env,omitempty"`               // Environment variables for the profile's terraform commands
 }
 
 // Config represents the application configuration
 type Config struct {
-	Profiles []Profile `json:"profiles"`
+	Profiles []Profile           `json:"profiles"`
+	Groups   map[string][]string `json:"groups,omitempty"`
+	Aliases  map[string]string   `json:"aliases,omitempty"` // Short name -> profile name
 }
 
-// DetectProfiles scans the filesystem and returns detected profiles
+// RecursiveDiscovery also detects the profiles of terraform modules in subdirectories
+var RecursiveDiscovery bool
+
+// DetectProfiles scans the filesystem and returns detected profiles. With recursive discovery,
+// every subdirectory holding its own backend and vars directories is a module whose profiles are
+// named by the module path, e.g. networking/prod.
 func DetectProfiles() (*Config, error) {
-	backendDir := "backend"
-	varsDir := "vars"
+	manifest, err := LoadManifest(ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	backendDir, varsDir := manifest.Dirs()
+
+	profiles, err := detectModuleProfiles("", backendDir, varsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if RecursiveDiscovery || manifest.Recursive {
+		moduleDirs, err := findModuleDirs(backendDir, varsDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, moduleDir := range moduleDirs {
+			moduleProfiles, err := detectModuleProfiles(moduleDir, backendDir, varsDir)
+			if err != nil {
+				return nil, err
+			}
+			profiles = append(profiles, moduleProfiles...)
+		}
+	}
+
+	if len(profiles) == 0 {
+		return &Config{Profiles: []Profile{}}, nil
+	}
+	applyManifest(manifest, profiles)
+
+	config := &Config{Profiles: profiles, Groups: manifest.Groups, Aliases: manifest.Aliases}
+	if err := ValidateGroups(config); err != nil {
+		return nil, err
+	}
+	if err := ValidateAliases(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// RecursiveEnabled reports whether recursive discovery is enabled by flag or in the manifest
+func RecursiveEnabled() bool {
+	if RecursiveDiscovery {
+		return true
+	}
+	manifest, err := LoadManifest(ManifestFile)
+	return err == nil && manifest.Recursive
+}
+
+// detectModuleProfiles pairs the backend and var files of the module in moduleDir ("" for the
+// current directory); profiles of nested modules are prefixed with the module path
+func detectModuleProfiles(moduleDir, backendDir, varsDir string) ([]Profile, error) {
+	// Backend files are optional when the backend is configured from the environment
+	envBackend := len(BackendConfigFromEnv()) > 0
 
 	// Check if required directories exist
+	dirExists := make(map[string]bool)
 	for _, dir := range []string{backendDir, varsDir} {
-		exists, err := utils.CheckDirExists(dir)
+		exists, err := utils.CheckDirExists(filepath.Join(moduleDir, dir))
 		if err != nil {
-			return nil, fmt.Errorf("error checking %s directory: %w", dir, err)
+			return nil, fmt.Errorf("error checking %s directory: %w", filepath.Join(moduleDir, dir), err)
 		}
-		if !exists {
-			return &Config{Profiles: []Profile{}}, nil
+		if !exists && !(dir == backendDir && envBackend) {
+			return []Profile{}, nil
 		}
+		dirExists[dir] = exists
 	}
 
 	// Scan for backend and var files
-	backendFiles, err := utils.ScanFilesWithExtension(backendDir, ".tfbackend")
-	if err != nil {
-		return nil, fmt.Errorf("error scanning backend directory: %w", err)
+	backendFiles := make(map[string]string)
+	if dirExists[backendDir] {
+		var err error
+		backendFiles, err = scanProfileFiles(filepath.Join(moduleDir, backendDir), ".tfbackend")
+		if err != nil {
+			return nil, fmt.Errorf("error scanning backend directory: %w", err)
+		}
 	}
 
-	varFiles, err := utils.ScanFilesWithExtension(varsDir, ".tfvars")
+	varFiles, err := scanProfileFiles(filepath.Join(moduleDir, varsDir), ".tfvars")
 	if err != nil {
 		return nil, fmt.Errorf("error scanning vars directory: %w", err)
 	}
@@ -58,21 +136,282 @@ func DetectProfiles() (*Config, error) {
 				VarFile:       varFile,
 				BackendDir:    backendDir,
 				VarsDir:       varsDir,
+				ModuleDir:     moduleDir,
 				LastUsed:      "",
 			})
 		}
 	}
 
-	return &Config{Profiles: profiles}, nil
+	if envBackend {
+		for profileName, varFile := range varFiles {
+			if _, exists := backendFiles[profileName]; !exists {
+				profiles = append(profiles, Profile{
+					Name:       profileName,
+					VarFile:    varFile,
+					BackendDir: backendDir,
+					VarsDir:    varsDir,
+					ModuleDir:  moduleDir,
+				})
+			}
+		}
+	}
+
+	if err := loadProfileEnv(profiles); err != nil {
+		return nil, err
+	}
+	if moduleDir != "" {
+		for i := range profiles {
+			profiles[i].Name = path.Join(filepath.ToSlash(moduleDir), profiles[i].Name)
+		}
+	}
+	return profiles, nil
+}
+
+// findModuleDirs returns the subdirectories holding their own vars directory and, unless the
+// backend is configured from the environment, backend directory. Hidden directories such as
+// .terraform and tapper's workspaces are skipped.
+func findModuleDirs(backendDir, varsDir string) ([]string, error) {
+	envBackend := len(BackendConfigFromEnv()) > 0
+
+	var moduleDirs []string
+	err := filepath.WalkDir(".", func(dir string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() || dir == "." {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		hasVars, _ := utils.CheckDirExists(filepath.Join(dir, varsDir))
+		hasBackend, _ := utils.CheckDirExists(filepath.Join(dir, backendDir))
+		if hasVars && (hasBackend || envBackend) {
+			moduleDirs = append(moduleDirs, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error searching for modules: %w", err)
+	}
+	return moduleDirs, nil
+}
+
+// VarFilePath returns the profile's var file relative to the current directory
+func (p Profile) VarFilePath() string {
+	return filepath.Join(p.ModuleDir, p.VarsDir, p.VarFile)
+}
+
+// BackendConfigPath returns the profile's backend config file relative to the current directory
+func (p Profile) BackendConfigPath() string {
+	return filepath.Join(p.ModuleDir, p.BackendDir, p.BackendConfig)
+}
+
+// RedactedEnvValue replaces profile environment values in output, since they may be credentials
+const RedactedEnvValue = "***"
+
+// Redacted returns a copy of the profile safe to print, with its environment values replaced
+// by RedactedEnvValue and only the variable names kept
+func (p Profile) Redacted() Profile {
+	if len(p.Env) == 0 {
+		return p
+	}
+	env := make(map[string]string, len(p.Env))
+	for name := range p.Env {
+		env[name] = RedactedEnvValue
+	}
+	p.Env = env
+	return p
+}
+
+// ProfileEnvExtension is the extension of the optional per-profile env file in the vars directory
+const ProfileEnvExtension = ".env"
+
+// loadProfileEnv reads each profile's <profile>.env file from its vars directory, when present
+func loadProfileEnv(profiles []Profile) error {
+	for i := range profiles {
+		envPath := filepath.Join(profiles[i].ModuleDir, profiles[i].VarsDir, profiles[i].Name+ProfileEnvExtension)
+		if _, err := os.Stat(envPath); os.IsNotExist(err) {
+			continue
+		}
+		env, err := utils.ParseEnvFile(envPath)
+		if err != nil {
+			return fmt.Errorf("error loading environment for profile %s: %w", profiles[i].Name, err)
+		}
+		profiles[i].Env = env
+	}
+	return nil
+}
+
+// scanProfileFiles finds files with extension or its JSON variant (e.g. .tfvars.json) keyed by
+// profile name. When both exist for a profile the native HCL file takes precedence.
+func scanProfileFiles(dir, extension string) (map[string]string, error) {
+	files, err := utils.ScanFilesWithExtension(dir, extension)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonFiles, err := utils.ScanFilesWithExtension(dir, extension+".json")
+	if err != nil {
+		return nil, err
+	}
+
+	for name, jsonFile := range jsonFiles {
+		if file, exists := files[name]; exists {
+			fmt.Fprintf(os.Stderr, "Warning: both %s and %s exist for profile '%s', using %s\n", file, jsonFile, name, file)
+			continue
+		}
+		files[name] = jsonFile
+	}
+	return files, nil
+}
+
+// UnmatchedFile is a backend or var file that does not form a profile
+type UnmatchedFile struct {
+	Path   string
+	Reason string
+}
+
+// FindUnmatchedFiles reports the backend and var files that DetectProfiles ignores, with the reason
+func FindUnmatchedFiles() ([]UnmatchedFile, error) {
+	manifest, err := LoadManifest(ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	backendDir, varsDir := manifest.Dirs()
+	envBackend := len(BackendConfigFromEnv()) > 0
+
+	var unmatched []UnmatchedFile
+	files := make(map[string]map[string]string)
+	for _, dir := range []struct{ path, extension string }{{backendDir, ".tfbackend"}, {varsDir, ".tfvars"}} {
+		exists, err := utils.CheckDirExists(dir.path)
+		if err != nil {
+			return nil, fmt.Errorf("error checking %s directory: %w", dir.path, err)
+		}
+		if !exists {
+			files[dir.path] = map[string]string{}
+			continue
+		}
+
+		files[dir.path], err = scanProfileFiles(dir.path, dir.extension)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning %s directory: %w", dir.path, err)
+		}
+
+		others, err := unrecognizedFiles(dir.path, dir.extension)
+		if err != nil {
+			return nil, err
+		}
+		unmatched = append(unmatched, others...)
+	}
+
+	backendFiles, varFiles := files[backendDir], files[varsDir]
+	for _, name := range sortedKeys(backendFiles) {
+		if _, exists := varFiles[name]; !exists {
+			unmatched = append(unmatched, UnmatchedFile{
+				Path:   filepath.Join(backendDir, backendFiles[name]),
+				Reason: missingPairReason("var", filepath.Join(varsDir, name+".tfvars"), name, varFiles),
+			})
+		}
+	}
+	// Var files without a backend file are profiles of their own when the backend comes from the environment
+	if !envBackend {
+		for _, name := range sortedKeys(varFiles) {
+			if _, exists := backendFiles[name]; !exists {
+				unmatched = append(unmatched, UnmatchedFile{
+					Path:   filepath.Join(varsDir, varFiles[name]),
+					Reason: missingPairReason("backend", filepath.Join(backendDir, name+".tfbackend"), name, backendFiles),
+				})
+			}
+		}
+	}
+	return unmatched, nil
+}
+
+// missingPairReason explains a missing counterpart file, suggesting a similarly named candidate
+func missingPairReason(kind, expected, name string, candidates map[string]string) string {
+	reason := fmt.Sprintf("no matching %s file %s", kind, expected)
+	for _, candidate := range sortedKeys(candidates) {
+		if strings.EqualFold(candidate, name) || editDistance(candidate, name) <= 2 {
+			return fmt.Sprintf("%s (did you mean %s?)", reason, candidates[candidate])
+		}
+	}
+	return reason
+}
+
+// unrecognizedFiles lists the files directly in dir that do not have the profile extension
+func unrecognizedFiles(dir, extension string) ([]UnmatchedFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s directory: %w", dir, err)
+	}
+
+	var unmatched []UnmatchedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, extension) || strings.HasSuffix(name, extension+".json") ||
+			strings.HasSuffix(name, ProfileEnvExtension) {
+			continue
+		}
+		unmatched = append(unmatched, UnmatchedFile{
+			Path:   filepath.Join(dir, name),
+			Reason: fmt.Sprintf("not a %s or %s.json file", extension, extension),
+		})
+	}
+	return unmatched, nil
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
 }
 
 // LoadConfig loads the configuration by detecting profiles from filesystem
 func LoadConfig() (*Config, error) {
-	return DetectProfiles()
+	cfg, err := DetectProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := loadUsage(UsageStateFile)
+	if err != nil {
+		return nil, err
+	}
+	applyUsage(usage, cfg.Profiles)
+
+	return cfg, nil
 }
 
-// GetProfile gets a profile by name
+// GetProfile gets a profile by name or alias
 func GetProfile(config *Config, name string) (Profile, bool) {
+	name = resolveAlias(config, name)
 	for _, profile := range config.Profiles {
 		if profile.Name == name {
 			return profile, true
@@ -81,6 +420,52 @@ func GetProfile(config *Config, name string) (Profile, bool) {
 	return Profile{}, false
 }
 
+// hasProfile reports whether a detected profile has exactly this name, without resolving aliases
+func hasProfile(config *Config, name string) bool {
+	for _, profile := range config.Profiles {
+		if profile.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveAlias returns the profile name an alias stands for, or name itself if it is no alias
+func resolveAlias(config *Config, name string) string {
+	if target, exists := config.Aliases[name]; exists {
+		return target
+	}
+	return name
+}
+
+// AliasNames returns the sorted names of all profile aliases
+func AliasNames(config *Config) []string {
+	names := make([]string, 0, len(config.Aliases))
+	for name := range config.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateAliases checks that every alias names a detected profile and does not shadow a profile.
+// Clashes with group names are reported by ValidateGroups.
+func ValidateAliases(config *Config) error {
+	var errs []error
+	for _, alias := range AliasNames(config) {
+		if hasProfile(config, alias) {
+			errs = append(errs, fmt.Errorf("alias '%s' has the same name as a profile", alias))
+		}
+		if target := config.Aliases[alias]; !hasProfile(config, target) {
+			errs = append(errs, fmt.Errorf("alias '%s' references unknown profile '%s'", alias, target))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid aliases in %s: %w", ManifestFile, errors.Join(errs...))
+	}
+	return nil
+}
+
 // ListProfiles returns a list of all profile names
 func ListProfiles(config *Config) []string {
 	names := make([]string, len(config.Profiles))
@@ -89,3 +474,95 @@ func ListProfiles(config *Config) []string {
 	}
 	return names
 }
+
+// ValidateGroups checks that every group member resolves to a detected profile and that no group
+// shares its name with a profile or alias
+func ValidateGroups(config *Config) error {
+	var errs []error
+	for _, group := range GroupNames(config) {
+		if hasProfile(config, group) {
+			errs = append(errs, fmt.Errorf("group '%s' has the same name as a profile", group))
+		}
+		if _, isAlias := config.Aliases[group]; isAlias {
+			errs = append(errs, fmt.Errorf("group '%s' has the same name as an alias", group))
+		}
+		for _, member := range config.Groups[group] {
+			if _, exists := GetProfile(config, member); !exists {
+				errs = append(errs, fmt.Errorf("group '%s' references unknown profile '%s'", group, member))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid groups in %s: %w", ManifestFile, errors.Join(errs...))
+	}
+	return nil
+}
+
+// GroupNames returns the sorted names of all profile groups
+func GroupNames(config *Config) []string {
+	names := make([]string, 0, len(config.Groups))
+	for name := range config.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandGroups replaces group names with their member profiles and aliases with the profile they
+// stand for, leaving other names unchanged
+func ExpandGroups(config *Config, names []string) []string {
+	var expanded []string
+	for _, name := range names {
+		if members, exists := config.Groups[name]; exists {
+			for _, member := range members {
+				expanded = append(expanded, resolveAlias(config, member))
+			}
+			continue
+		}
+		expanded = append(expanded, resolveAlias(config, name))
+	}
+	return expanded
+}
+
+// ExcludeProfiles removes the excluded profiles (or groups) from names. An exclusion that matches
+// no profile or group is an error, so a typo cannot silently leave a profile selected.
+func ExcludeProfiles(config *Config, names, excludes []string) ([]string, error) {
+	excluded := make(map[string]bool)
+	var unknown []string
+	for _, name := range excludes {
+		if _, isGroup := config.Groups[name]; !isGroup {
+			if _, exists := GetProfile(config, name); !exists {
+				unknown = append(unknown, name)
+				continue
+			}
+		}
+		for _, member := range ExpandGroups(config, []string{name}) {
+			excluded[member] = true
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("excluded profile(s) not found: %s", strings.Join(unknown, ", "))
+	}
+
+	var remaining []string
+	for _, name := range names {
+		if !excluded[name] {
+			remaining = append(remaining, name)
+		}
+	}
+	return remaining, nil
+}
+
+// ValidateVarFiles checks the syntax of every profile's var file and reports all failures
+func ValidateVarFiles(profiles []Profile) error {
+	var errs []error
+	for _, profile := range profiles {
+		if profile.VarFile == "" {
+			continue
+		}
+		if err := utils.ValidateTFVars(profile.VarFilePath()); err != nil {
+			errs = append(errs, fmt.Errorf("profile %s: %w", profile.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -3,6 +3,7 @@ package terraform
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -68,6 +69,220 @@ func TestDetectProfiles(t *testing.T) {
 	}
 }
 
+func TestDetectProfilesCustomDirs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	os.MkdirAll(filepath.Join("config", "backends"), 0755)
+	os.MkdirAll(filepath.Join("config", "tfvars"), 0755)
+	os.WriteFile(filepath.Join("config", "backends", "dev.tfbackend"), []byte("bucket = \"dev-bucket\""), 0644)
+	os.WriteFile(filepath.Join("config", "tfvars", "dev.tfvars"), []byte("environment = \"dev\""), 0644)
+	os.WriteFile(ManifestFile, []byte(`{"backend_dir": "config/backends", "vars_dir": "config/tfvars"}`), 0644)
+
+	config, err := DetectProfiles()
+	if err != nil {
+		t.Fatalf("Expected no error detecting profiles, got: %v", err)
+	}
+	if len(config.Profiles) != 1 {
+		t.Fatalf("Expected 1 profile from the configured directories, got: %d", len(config.Profiles))
+	}
+
+	profile := config.Profiles[0]
+	if profile.BackendDir != filepath.Join("config", "backends") || profile.VarsDir != filepath.Join("config", "tfvars") {
+		t.Errorf("Expected configured directories on the profile, got backend=%s vars=%s", profile.BackendDir, profile.VarsDir)
+	}
+}
+
+func TestDetectProfilesRecursive(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	for _, module := range []string{".", "networking", filepath.Join("apps", "api"), ".hidden"} {
+		os.MkdirAll(filepath.Join(module, "backend"), 0755)
+		os.MkdirAll(filepath.Join(module, "vars"), 0755)
+		os.WriteFile(filepath.Join(module, "backend", "prod.tfbackend"), []byte("bucket = \"state\""), 0644)
+		os.WriteFile(filepath.Join(module, "vars", "prod.tfvars"), []byte("environment = \"prod\""), 0644)
+	}
+	os.WriteFile(filepath.Join("networking", "vars", "prod.env"), []byte("TF_LOG=INFO\n"), 0644)
+
+	config, err := DetectProfiles()
+	if err != nil || len(config.Profiles) != 1 {
+		t.Fatalf("Expected only the root profile without recursive discovery, got: %v, %v", config, err)
+	}
+
+	os.WriteFile(ManifestFile, []byte(`{"recursive": true, "profiles": {"networking/prod": {"parallelism": 4}}}`), 0644)
+	config, err = DetectProfiles()
+	if err != nil {
+		t.Fatalf("Expected no error detecting profiles, got: %v", err)
+	}
+
+	profiles := make(map[string]Profile)
+	for _, profile := range config.Profiles {
+		profiles[profile.Name] = profile
+	}
+	if len(profiles) != 3 {
+		t.Fatalf("Expected root, networking and apps/api profiles, got: %v", ListProfiles(config))
+	}
+	networking, exists := profiles["networking/prod"]
+	if !exists || networking.ModuleDir != "networking" || networking.BackendDir != "backend" {
+		t.Errorf("Expected a profile namespaced by its module, got: %+v", networking)
+	}
+	if networking.VarFilePath() != filepath.Join("networking", "vars", "prod.tfvars") {
+		t.Errorf("Expected the var file path inside the module, got: %s", networking.VarFilePath())
+	}
+	if networking.Parallelism != 4 || networking.Env["TF_LOG"] != "INFO" {
+		t.Errorf("Expected manifest settings and the module's env file, got: %+v", networking)
+	}
+	if _, exists := profiles["apps/api/prod"]; !exists {
+		t.Errorf("Expected a profile of the nested module apps/api, got: %v", ListProfiles(config))
+	}
+}
+
+func TestDetectProfilesJSONFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	os.MkdirAll("backend", 0755)
+	os.MkdirAll("vars", 0755)
+	os.WriteFile(filepath.Join("backend", "dev.tfbackend.json"), []byte(`{"bucket": "dev-bucket"}`), 0644)
+	os.WriteFile(filepath.Join("vars", "dev.tfvars.json"), []byte(`{"environment": "dev"}`), 0644)
+	os.WriteFile(filepath.Join("backend", "prod.tfbackend"), []byte("bucket = \"prod-bucket\""), 0644)
+	os.WriteFile(filepath.Join("vars", "prod.tfvars"), []byte("environment = \"prod\""), 0644)
+	os.WriteFile(filepath.Join("vars", "prod.tfvars.json"), []byte(`{"environment": "prod"}`), 0644)
+
+	config, err := DetectProfiles()
+	if err != nil {
+		t.Fatalf("Expected no error detecting profiles, got: %v", err)
+	}
+
+	dev, found := GetProfile(config, "dev")
+	if !found || dev.BackendConfig != "dev.tfbackend.json" || dev.VarFile != "dev.tfvars.json" {
+		t.Fatalf("Expected dev profile from JSON files, got: %+v", dev)
+	}
+	args := NewCommandBuilder().WithVarFile(dev.VarFile).buildTerraformCommand(&ExecutionOptions{Command: "plan"}).Args
+	if args[2] != "--var-file="+filepath.Join("vars", "dev.tfvars.json") {
+		t.Errorf("Expected JSON var file argument, got: %v", args)
+	}
+
+	// The HCL file takes precedence when both formats exist
+	prod, found := GetProfile(config, "prod")
+	if !found || prod.VarFile != "prod.tfvars" {
+		t.Errorf("Expected prod.tfvars to take precedence, got: %+v", prod)
+	}
+}
+
+func TestProfileGroups(t *testing.T) {
+	config := &Config{
+		Profiles: []Profile{{Name: "web"}, {Name: "cdn"}, {Name: "dns"}, {Name: "db"}},
+		Groups:   map[string][]string{"frontend": {"web", "cdn", "dns"}},
+	}
+
+	if err := ValidateGroups(config); err != nil {
+		t.Fatalf("Expected valid groups, got: %v", err)
+	}
+
+	expanded := ExpandGroups(config, []string{"frontend", "db"})
+	expected := []string{"web", "cdn", "dns", "db"}
+	if len(expanded) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, expanded)
+	}
+	for i, name := range expected {
+		if expanded[i] != name {
+			t.Errorf("Expected %v, got %v", expected, expanded)
+		}
+	}
+
+	config.Groups["backend"] = []string{"db", "cache"}
+	err := ValidateGroups(config)
+	if err == nil || !strings.Contains(err.Error(), "group 'backend' references unknown profile 'cache'") {
+		t.Errorf("Expected unknown member error, got: %v", err)
+	}
+}
+
+func TestProfileAliases(t *testing.T) {
+	config := &Config{
+		Profiles: []Profile{{Name: "acme-production-us-east-1"}, {Name: "acme-staging"}},
+		Groups:   map[string][]string{"all": {"prod", "acme-staging"}},
+		Aliases:  map[string]string{"prod": "acme-production-us-east-1"},
+	}
+	if err := ValidateAliases(config); err != nil {
+		t.Fatalf("Expected valid aliases, got: %v", err)
+	}
+
+	if profile, exists := GetProfile(config, "prod"); !exists || profile.Name != "acme-production-us-east-1" {
+		t.Errorf("Expected prod to resolve to its profile, got: %v, %v", profile.Name, exists)
+	}
+	expanded := ExpandGroups(config, []string{"prod", "all"})
+	if strings.Join(expanded, ",") != "acme-production-us-east-1,acme-production-us-east-1,acme-staging" {
+		t.Errorf("Expected aliases resolved in arguments and groups, got: %v", expanded)
+	}
+
+	config.Aliases["acme-staging"] = "acme-production-us-east-1"
+	config.Aliases["qa"] = "acme-qa"
+	err := ValidateAliases(config)
+	if err == nil || !strings.Contains(err.Error(), "alias 'acme-staging' has the same name as a profile") ||
+		!strings.Contains(err.Error(), "alias 'qa' references unknown profile 'acme-qa'") {
+		t.Errorf("Expected collision and unknown profile errors, got: %v", err)
+	}
+
+	// A group named like an alias clashes with the alias, not with the profile it stands for
+	delete(config.Aliases, "acme-staging")
+	config.Groups["prod"] = []string{"acme-staging"}
+	err = ValidateGroups(config)
+	if err == nil || !strings.Contains(err.Error(), "group 'prod' has the same name as an alias") ||
+		strings.Contains(err.Error(), "same name as a profile") {
+		t.Errorf("Expected only the alias collision error, got: %v", err)
+	}
+}
+
+func TestFindUnmatchedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(tempDir)
+
+	os.MkdirAll("backend", 0755)
+	os.MkdirAll("vars", 0755)
+	os.WriteFile(filepath.Join("backend", "dev.tfbackend"), []byte("bucket = \"dev\""), 0644)
+	os.WriteFile(filepath.Join("vars", "dev.tfvars"), []byte("environment = \"dev\""), 0644)
+	os.WriteFile(filepath.Join("backend", "staging.tfbackend"), []byte("bucket = \"staging\""), 0644)
+	os.WriteFile(filepath.Join("vars", "stagin.tfvars"), []byte("environment = \"staging\""), 0644)
+	os.WriteFile(filepath.Join("vars", "prod.tfvar"), []byte("environment = \"prod\""), 0644)
+
+	unmatched, err := FindUnmatchedFiles()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	reasons := make(map[string]string)
+	for _, file := range unmatched {
+		reasons[file.Path] = file.Reason
+	}
+	expected := map[string]string{
+		filepath.Join("backend", "staging.tfbackend"): "no matching var file vars/staging.tfvars (did you mean stagin.tfvars?)",
+		filepath.Join("vars", "stagin.tfvars"):        "no matching backend file backend/stagin.tfbackend (did you mean staging.tfbackend?)",
+		filepath.Join("vars", "prod.tfvar"):           "not a .tfvars or .tfvars.json file",
+	}
+	if len(reasons) != len(expected) {
+		t.Fatalf("Expected %d unmatched files, got: %v", len(expected), reasons)
+	}
+	for path, reason := range expected {
+		if reasons[path] != reason {
+			t.Errorf("Expected %s: %q, got: %q", path, reason, reasons[path])
+		}
+	}
+}
+
 func TestGetProfile(t *testing.T) {
 	config := &Config{
 		Profiles: []Profile{
@@ -119,3 +334,50 @@ func TestListProfiles(t *testing.T) {
 		}
 	}
 }
+
+func TestExcludeProfiles(t *testing.T) {
+	config := &Config{
+		Profiles: []Profile{{Name: "dev"}, {Name: "staging"}, {Name: "prod"}, {Name: "prod-eu"}},
+		Groups:   map[string][]string{"production": {"prod", "prod-eu"}},
+	}
+
+	remaining, err := ExcludeProfiles(config, ListProfiles(config), []string{"production", "dev"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0] != "staging" {
+		t.Errorf("Expected only staging to remain, got: %v", remaining)
+	}
+
+	// A typo must not silently keep the profile selected
+	if _, err := ExcludeProfiles(config, ListProfiles(config), []string{"prdo"}); err == nil {
+		t.Error("Expected an error for an unknown excluded profile")
+	}
+}
+
+func TestProfileRedacted(t *testing.T) {
+	profile := Profile{Name: "prod", Env: map[string]string{"AWS_PROFILE": "prod", "TF_TOKEN": "secret"}}
+
+	redacted := profile.Redacted()
+	if len(redacted.Env) != 2 || redacted.Env["TF_TOKEN"] != RedactedEnvValue || redacted.Env["AWS_PROFILE"] != RedactedEnvValue {
+		t.Errorf("Expected every env value to be redacted, got: %v", redacted.Env)
+	}
+	if profile.Env["TF_TOKEN"] != "secret" {
+		t.Errorf("Expected the original profile to keep its env, got: %v", profile.Env)
+	}
+}
+
+func TestLoadUsageIgnoresCorruptState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"lastused": {"dev": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := loadUsage(path)
+	if err != nil {
+		t.Fatalf("Expected a corrupt usage state to be ignored, got: %v", err)
+	}
+	if len(state.LastUsed) != 0 {
+		t.Errorf("Expected an empty usage state, got: %v", state.LastUsed)
+	}
+}
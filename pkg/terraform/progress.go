@@ -0,0 +1,48 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+
+	"tapper/pkg/utils"
+)
+
+// progressBarWidth is the number of cells of the aggregate progress bar
+const progressBarWidth = 20
+
+// progressBar renders done out of total as a fixed-width bar, e.g. [██████░░░░]
+func progressBar(done, total, width int) string {
+	filled := width
+	if total > 0 {
+		filled = done * width / total
+	}
+	filled = max(0, min(filled, width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// reportCompletion shows how many profiles of the run have completed. On a terminal the count
+// gets a bar, and in quiet mode it heads the redrawn progress line; otherwise it is printed as
+// a line of its own, naming the profile that just finished.
+func (h *StreamingOutputHandler) reportCompletion(progress ProgressiveResult) {
+	h.outputMutex.Lock()
+	defer h.outputMutex.Unlock()
+
+	count := fmt.Sprintf("%d/%d profiles complete", progress.Index, progress.Total)
+	terminal := utils.StdoutIsTerminal()
+	if terminal {
+		count = progressBar(progress.Index, progress.Total, progressBarWidth) + " " + count
+	}
+
+	if h.Quiet && terminal {
+		h.completed = count
+		h.printProgressLine()
+		return
+	}
+
+	outcome := "✅"
+	if !progress.Result.Success {
+		outcome = "❌"
+	}
+	profileColor := h.color(h.colorManager.GetProfileColor(progress.Result.ProfileName))
+	fmt.Fprintf(h.stdout(), "%s (%s%s%s: %s)\n", count, profileColor, progress.Result.ProfileName, h.color(utils.ColorReset), outcome)
+}
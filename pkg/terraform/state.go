@@ -0,0 +1,176 @@
+package terraform
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tapper/pkg/utils"
+)
+
+// PlanStateFile is the file used to persist review progress between runs
+const PlanStateFile = ".tapper-plan.json"
+
+// ReviewPlansDir holds copies of the terraform plans under review, so a resumed review can apply
+// them after the workspaces of the interrupted run are gone
+const ReviewPlansDir = ".tapper/plans"
+
+// SavePlanState writes the execution plan and its review progress to disk
+func SavePlanState(plan *ExecutionPlan, path string) error {
+	for i := range plan.Results {
+		if plan.Results[i].Error != nil {
+			plan.Results[i].ErrorMessage = plan.Results[i].Error.Error()
+		}
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding plan state: %w", err)
+	}
+
+	// Plan output may contain secrets; a file written by an older version is tightened as well
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing plan state %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("error writing plan state %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadPlanState reads a previously saved execution plan from disk
+func LoadPlanState(path string) (*ExecutionPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan state %s: %w", path, err)
+	}
+
+	var plan ExecutionPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("error decoding plan state %s: %w", path, err)
+	}
+
+	for i := range plan.Results {
+		if plan.Results[i].ErrorMessage != "" {
+			plan.Results[i].Error = errors.New(plan.Results[i].ErrorMessage)
+		}
+	}
+	return &plan, nil
+}
+
+// RemovePlanState deletes the persisted plan state and the copied review plans if they exist
+func RemovePlanState(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing plan state %s: %w", path, err)
+	}
+	if err := os.RemoveAll(ReviewPlansDir); err != nil {
+		return fmt.Errorf("error removing review plans %s: %w", ReviewPlansDir, err)
+	}
+	return nil
+}
+
+// preserveReviewPlans copies every profile's terraform plan out of its workspace into
+// ReviewPlansDir and points the result at the copy, which outlives the workspace
+func preserveReviewPlans(plan *ExecutionPlan) error {
+	for i, result := range plan.Results {
+		if result.PlanFile == "" {
+			continue
+		}
+		if err := os.MkdirAll(ReviewPlansDir, 0700); err != nil {
+			return fmt.Errorf("error creating plan directory %s: %w", ReviewPlansDir, err)
+		}
+		target, err := filepath.Abs(filepath.Join(ReviewPlansDir, utils.FileSafeName(result.ProfileName)+".tfplan"))
+		if err != nil {
+			return err
+		}
+		if err := copyPlanFile(result.PlanFile, target); err != nil {
+			return fmt.Errorf("error saving plan for profile %s: %w", result.ProfileName, err)
+		}
+		plan.Results[i].PlanFile = target
+	}
+	return nil
+}
+
+// checkReviewPlans reports profiles still to be reviewed whose copied plan is gone
+func checkReviewPlans(plan *ExecutionPlan) error {
+	for _, result := range plan.Results {
+		if result.PlanFile == "" || containsString(plan.ReviewedProfiles, result.ProfileName) && !containsString(plan.ApprovedProfiles, result.ProfileName) {
+			continue
+		}
+		if exists, _ := utils.CheckFileOrDirExists(result.PlanFile); !exists {
+			return fmt.Errorf("saved plan of profile %s is missing (%s); run again without --resume", result.ProfileName, result.PlanFile)
+		}
+	}
+	return nil
+}
+
+// SaveApprovedPlan writes the reviewed plan to path and copies the saved terraform plan of every
+// approved profile into a "<path>.plans" directory next to it, so it can be applied later with
+// LoadApprovedPlan after the workspaces are gone
+func SaveApprovedPlan(plan *ExecutionPlan, path string) error {
+	plansDir := strings.TrimSuffix(path, filepath.Ext(path)) + ".plans"
+	if err := os.MkdirAll(plansDir, 0700); err != nil {
+		return fmt.Errorf("error creating plan directory %s: %w", plansDir, err)
+	}
+
+	saved := *plan
+	saved.Results = make([]ExecutionResult, len(plan.Results))
+	for i, result := range plan.Results {
+		if result.PlanFile != "" && containsString(plan.ApprovedProfiles, result.ProfileName) {
+			target := filepath.Join(plansDir, utils.FileSafeName(result.ProfileName)+".tfplan")
+			if err := copyPlanFile(result.PlanFile, target); err != nil {
+				return fmt.Errorf("error saving plan for profile %s: %w", result.ProfileName, err)
+			}
+			// Stored relative to the plan file, so the pair can be moved together
+			result.PlanFile = filepath.Join(filepath.Base(plansDir), utils.FileSafeName(result.ProfileName)+".tfplan")
+		} else {
+			result.PlanFile = ""
+		}
+		saved.Results[i] = result
+	}
+
+	return SavePlanState(&saved, path)
+}
+
+// LoadApprovedPlan reads a plan written by SaveApprovedPlan, resolving its terraform plan files
+func LoadApprovedPlan(path string) (*ExecutionPlan, error) {
+	plan, err := LoadPlanState(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Terraform applies the plans from inside the workspaces, so the paths must be absolute
+	for i := range plan.Results {
+		if plan.Results[i].PlanFile != "" && !filepath.IsAbs(plan.Results[i].PlanFile) {
+			planFile, err := filepath.Abs(filepath.Join(filepath.Dir(path), plan.Results[i].PlanFile))
+			if err != nil {
+				return nil, fmt.Errorf("error resolving plan file of profile %s: %w", plan.Results[i].ProfileName, err)
+			}
+			plan.Results[i].PlanFile = planFile
+		}
+	}
+	return plan, nil
+}
+
+// copyPlanFile copies a terraform plan file; plans may contain secrets, so the copy is private
+func copyPlanFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,141 @@
+package terraform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PlanStateFile)
+
+	plan := &ExecutionPlan{
+		Command:  "apply",
+		Profiles: []Profile{{Name: "dev"}, {Name: "prod"}},
+		Results: []ExecutionResult{
+			{ProfileName: "dev", Success: true, Output: "No changes."},
+			{ProfileName: "prod", Error: errors.New("exit status 1")},
+		},
+		ApprovedProfiles: []string{"dev"},
+		ReviewedProfiles: []string{"dev"},
+	}
+
+	if err := SavePlanState(plan, path); err != nil {
+		t.Fatalf("Expected no error saving plan state, got: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected plan state to exist, got: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected plan state to be readable by the owner only, got: %v", info.Mode().Perm())
+	}
+
+	loaded, err := LoadPlanState(path)
+	if err != nil {
+		t.Fatalf("Expected no error loading plan state, got: %v", err)
+	}
+
+	if loaded.Command != "apply" {
+		t.Errorf("Expected command 'apply', got: %s", loaded.Command)
+	}
+	if len(loaded.Results) != 2 {
+		t.Fatalf("Expected 2 results, got: %d", len(loaded.Results))
+	}
+	if loaded.Results[1].Error == nil || loaded.Results[1].Error.Error() != "exit status 1" {
+		t.Errorf("Expected error to be restored, got: %v", loaded.Results[1].Error)
+	}
+	if len(loaded.ReviewedProfiles) != 1 || loaded.ReviewedProfiles[0] != "dev" {
+		t.Errorf("Expected reviewed profiles [dev], got: %v", loaded.ReviewedProfiles)
+	}
+
+	if err := RemovePlanState(path); err != nil {
+		t.Fatalf("Expected no error removing plan state, got: %v", err)
+	}
+	if err := RemovePlanState(path); err != nil {
+		t.Errorf("Expected removing a missing plan state to succeed, got: %v", err)
+	}
+}
+
+func TestSaveApprovedPlan(t *testing.T) {
+	workspaceDir := t.TempDir()
+	devPlan := filepath.Join(workspaceDir, PlanFileName)
+	os.WriteFile(devPlan, []byte("plan-bytes"), 0644)
+
+	plan := &ExecutionPlan{
+		Command:  "apply",
+		Profiles: []Profile{{Name: "dev"}, {Name: "prod"}},
+		Results: []ExecutionResult{
+			{ProfileName: "dev", Success: true, PlanFile: devPlan},
+			{ProfileName: "prod", Success: true, PlanFile: filepath.Join(workspaceDir, "unapproved.tfplan")},
+		},
+		ApprovedProfiles: []string{"dev"},
+	}
+
+	path := filepath.Join(t.TempDir(), "release.json")
+	if err := SaveApprovedPlan(plan, path); err != nil {
+		t.Fatalf("Expected no error saving plan, got: %v", err)
+	}
+	if plan.Results[0].PlanFile != devPlan {
+		t.Errorf("Expected the original plan to be left unchanged, got: %s", plan.Results[0].PlanFile)
+	}
+
+	loaded, err := LoadApprovedPlan(path)
+	if err != nil {
+		t.Fatalf("Expected no error loading plan, got: %v", err)
+	}
+	expected := filepath.Join(filepath.Dir(path), "release.plans", "dev.tfplan")
+	if loaded.Results[0].PlanFile != expected {
+		t.Errorf("Expected plan file %s, got %s", expected, loaded.Results[0].PlanFile)
+	}
+	if data, _ := os.ReadFile(expected); string(data) != "plan-bytes" {
+		t.Errorf("Expected the terraform plan to be copied, got: %q", data)
+	}
+	if loaded.Results[1].PlanFile != "" {
+		t.Errorf("Expected no plan file for unapproved profile, got: %s", loaded.Results[1].PlanFile)
+	}
+
+	// A plan loaded by a relative path still points at its files from inside the workspaces
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(filepath.Dir(path))
+	if loaded, err = LoadApprovedPlan("release.json"); err != nil {
+		t.Fatalf("Expected no error loading plan, got: %v", err)
+	}
+	if !filepath.IsAbs(loaded.Results[0].PlanFile) {
+		t.Errorf("Expected an absolute plan file, got %s", loaded.Results[0].PlanFile)
+	}
+}
+
+func TestPreserveReviewPlans(t *testing.T) {
+	oldDir, _ := os.Getwd()
+	defer os.Chdir(oldDir)
+	os.Chdir(t.TempDir())
+
+	workspacePlan := filepath.Join(t.TempDir(), PlanFileName)
+	os.WriteFile(workspacePlan, []byte("plan-bytes"), 0644)
+	plan := &ExecutionPlan{
+		Command: "apply",
+		Results: []ExecutionResult{{ProfileName: "dev", Success: true, PlanFile: workspacePlan}},
+	}
+	if err := preserveReviewPlans(plan); err != nil {
+		t.Fatalf("Expected no error preserving plans, got: %v", err)
+	}
+
+	// The workspace goes away with the interrupted run; the copy remains for --resume
+	os.Remove(workspacePlan)
+	if data, _ := os.ReadFile(plan.Results[0].PlanFile); string(data) != "plan-bytes" {
+		t.Errorf("Expected the plan to be copied out of the workspace, got: %q", data)
+	}
+	if err := checkReviewPlans(plan); err != nil {
+		t.Errorf("Expected the copied plan to be found, got: %v", err)
+	}
+
+	if err := RemovePlanState(PlanStateFile); err != nil {
+		t.Fatalf("Expected no error removing plan state, got: %v", err)
+	}
+	if err := checkReviewPlans(plan); err == nil {
+		t.Error("Expected an error once the copied plan is gone")
+	}
+}
@@ -1,7 +1,13 @@
 package terraform
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 	"tapper/pkg/utils"
@@ -14,63 +20,428 @@ type StreamingOutput struct {
 	Line        string
 	IsError     bool
 	Timestamp   time.Time
+	Finished    bool               // Marks the end of the profile's output; carries no line
+	Progress    *ProgressiveResult // Set on Finished markers: the profile's result and how many profiles completed
 }
 
 // StreamingOutputHandler handles the real-time display of streaming output
 type StreamingOutputHandler struct {
-	outputMutex  sync.Mutex
-	colorManager *utils.ProfileColorManager
+	outputMutex     sync.Mutex
+	colorManager    *utils.ProfileColorManager
+	Colorize        bool                         // Emit ANSI color codes
+	Interactive     bool                         // Accept profile names on stdin to toggle their output live
+	OnCancel        func(string) bool            // Cancels a running profile by name for "cancel <profile>" input
+	LogFile         string                       // File receiving every output line as JSON, appended to if it exists
+	Quiet           bool                         // Show a per-profile progress line instead of terraform's output
+	ShowProgress    bool                         // Show how many profiles of the run have completed
+	Grouped         bool                         // Buffer each profile's lines and print them as one block when it finishes
+	Heartbeat       time.Duration                // Interval of "still running" lines for silent profiles, 0 to disable
+	active          map[string]*profileActivity  // Running profiles, for heartbeats
+	TimestampFormat string                       // Go time layout of the line timestamps, empty to omit them
+	BufferLimit     int                          // Maximum bytes held back per profile in grouped mode, 0 for unlimited
+	buffered        map[string][]StreamingOutput // Profile -> lines held back in grouped mode
+	bufferedBytes   map[string]int               // Profile -> bytes of its held back lines
+	dropped         map[string]int               // Profile -> lines dropped to stay within BufferLimit
+	progress        []string                     // Profiles in the order they started, for quiet mode
+	completed       string                       // Rendered completion count, prefixed to the quiet mode line
+	states          map[string]string            // Profile -> progress state, for quiet mode
+	hidden          map[string]bool              // Profiles whose output is currently hidden
+	seen            map[string]bool              // Profiles that produced output in the current stream
+	out             io.Writer                    // Receives the output, os.Stdout when nil
+	finished        int                          // Finished markers received in the current stream
+}
+
+// profileActivity tracks when a running profile started and last produced output
+type profileActivity struct {
+	started    time.Time
+	lastOutput time.Time
+}
+
+// streamingLogEntry is the JSON line written to the log file for every streaming output
+type streamingLogEntry struct {
+	Profile   string    `json:"profile"`
+	Line      string    `json:"line"`
+	IsError   bool      `json:"is_error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DefaultTimestampFormat is the layout of the timestamp prefixed to every streamed line
+const DefaultTimestampFormat = "15:04:05.000"
+
+// DefaultHeartbeat is how long a profile may stay silent before a "still running" line is printed
+const DefaultHeartbeat = 30 * time.Second
+
+// stdout returns the writer receiving the output
+func (h *StreamingOutputHandler) stdout() io.Writer {
+	if h.out == nil {
+		return os.Stdout
+	}
+	return h.out
 }
 
 // NewStreamingOutputHandler creates a new streaming output handler
 func NewStreamingOutputHandler() *StreamingOutputHandler {
 	return &StreamingOutputHandler{
-		colorManager: utils.NewProfileColorManager(),
+		colorManager:    utils.NewProfileColorManager(),
+		Colorize:        true,
+		TimestampFormat: DefaultTimestampFormat,
+		hidden:          make(map[string]bool),
+		seen:            make(map[string]bool),
+		states:          make(map[string]string),
+		buffered:        make(map[string][]StreamingOutput),
+		bufferedBytes:   make(map[string]int),
+		dropped:         make(map[string]int),
+		active:          make(map[string]*profileActivity),
 	}
 }
 
 // DisplayStreamingOutput handles the real-time display of streaming output
 func (h *StreamingOutputHandler) DisplayStreamingOutput(streamChan <-chan StreamingOutput, done chan<- bool) {
-	for output := range streamChan {
-		h.outputMutex.Lock()
-		h.printStreamingLine(output)
-		h.outputMutex.Unlock()
+	stopControls := make(chan struct{})
+	controlsDone := make(chan struct{})
+	if h.Interactive && utils.IsInteractive() {
+		fmt.Fprintln(h.stdout(), "Type a profile name and press Enter to hide/show its output, 'all' to show every profile,")
+		fmt.Fprintln(h.stdout(), "or 'cancel <profile>' to stop a running profile while the others continue.")
+		go h.handleOutputControls(stopControls, controlsDone)
+	} else {
+		close(controlsDone)
+	}
+
+	logChan, logDone := h.startJSONLog()
+	h.finished = 0
+
+	// Live output toggling only makes sense for interleaved output
+	grouped := h.Grouped && !h.Quiet && !h.Interactive
+
+	// Heartbeats are printed from this loop too, so they never split another profile's line
+	var heartbeat <-chan time.Time
+	if h.Heartbeat > 0 && !h.Quiet {
+		ticker := time.NewTicker(h.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+stream:
+	for {
+		select {
+		case output, ok := <-streamChan:
+			if !ok {
+				break stream
+			}
+			h.handleOutput(output, grouped, logChan)
+		case now := <-heartbeat:
+			h.printHeartbeats(now, grouped)
+		}
+	}
+	remaining := make([]string, 0, len(h.buffered))
+	for profileName := range h.buffered {
+		remaining = append(remaining, profileName)
+	}
+	sort.Strings(remaining)
+	for _, profileName := range remaining {
+		h.flushProfile(profileName)
+	}
+	if h.Quiet && len(h.progress) > 0 {
+		if utils.StdoutIsTerminal() {
+			fmt.Fprintln(h.stdout())
+		}
+		h.progress = nil
+		h.completed = ""
+		h.states = make(map[string]string)
+	}
+	// The review reads stdin next, so the controls must have stopped reading it
+	close(stopControls)
+	<-controlsDone
+	if logChan != nil {
+		close(logChan)
+		<-logDone
 	}
 	done <- true
 }
 
+// handleOutput logs a streamed line and prints, buffers or tallies it according to the display mode
+func (h *StreamingOutputHandler) handleOutput(output StreamingOutput, grouped bool, logChan chan<- StreamingOutput) {
+	if output.Finished {
+		delete(h.active, output.ProfileName)
+		if grouped {
+			h.flushProfile(output.ProfileName)
+		}
+		// Numbered on arrival, so the completion lines count up in the order they are printed
+		h.finished++
+		if h.ShowProgress && output.Progress != nil {
+			progress := *output.Progress
+			progress.Index = h.finished
+			h.reportCompletion(progress)
+		}
+		return
+	}
+	if logChan != nil {
+		logChan <- output
+	}
+
+	h.outputMutex.Lock()
+	defer h.outputMutex.Unlock()
+	h.seen[output.ProfileName] = true
+	if activity, running := h.active[output.ProfileName]; running {
+		activity.lastOutput = time.Now()
+	} else {
+		h.active[output.ProfileName] = &profileActivity{started: time.Now(), lastOutput: time.Now()}
+	}
+
+	if h.Quiet {
+		h.updateProgress(output)
+	} else if grouped {
+		h.bufferLine(output)
+	} else if !h.hidden[output.ProfileName] || h.isCompletionMessage(output.Line) {
+		h.printStreamingLine(output)
+	}
+}
+
+// printHeartbeats prints a "still running" line for every running profile that has been silent
+// for a full heartbeat interval. Grouped output hides all lines until a profile finishes, so
+// every running profile gets a heartbeat there.
+func (h *StreamingOutputHandler) printHeartbeats(now time.Time, grouped bool) {
+	h.outputMutex.Lock()
+	defer h.outputMutex.Unlock()
+
+	profileNames := make([]string, 0, len(h.active))
+	for profileName := range h.active {
+		profileNames = append(profileNames, profileName)
+	}
+	sort.Strings(profileNames)
+
+	for _, profileName := range profileNames {
+		activity := h.active[profileName]
+		if h.hidden[profileName] || (!grouped && now.Sub(activity.lastOutput) < h.Heartbeat) {
+			continue
+		}
+		h.printStreamingLine(StreamingOutput{
+			ProfileName: profileName,
+			Line:        fmt.Sprintf("⏳ still running (%s)", now.Sub(activity.started).Round(time.Second)),
+			Timestamp:   now,
+		})
+	}
+}
+
+// bufferLine holds back a line in grouped mode, dropping the profile's oldest lines once its
+// buffer exceeds BufferLimit, like the tail kept of the captured output
+func (h *StreamingOutputHandler) bufferLine(output StreamingOutput) {
+	profileName := output.ProfileName
+	lines := append(h.buffered[profileName], output)
+	size := h.bufferedBytes[profileName] + len(output.Line)
+	for h.BufferLimit > 0 && size > h.BufferLimit && len(lines) > 1 {
+		size -= len(lines[0].Line)
+		lines = lines[1:]
+		h.dropped[profileName]++
+	}
+	h.buffered[profileName] = lines
+	h.bufferedBytes[profileName] = size
+}
+
+// flushProfile prints the lines buffered for a profile as one block between a header and footer
+func (h *StreamingOutputHandler) flushProfile(profileName string) {
+	lines, buffered := h.buffered[profileName]
+	if !buffered {
+		return
+	}
+	dropped := h.dropped[profileName]
+	delete(h.buffered, profileName)
+	delete(h.bufferedBytes, profileName)
+	delete(h.dropped, profileName)
+
+	profileColor := h.color(h.colorManager.GetProfileColor(profileName))
+	reset := h.color(utils.ColorReset)
+	fmt.Fprintf(h.stdout(), "%s===== %s =====%s\n", profileColor, profileName, reset)
+	if dropped > 0 {
+		fmt.Fprintf(h.stdout(), "... %d earlier line(s) dropped to stay within the output buffer\n", dropped)
+	}
+	for _, output := range lines {
+		h.printStreamingLine(output)
+	}
+	fmt.Fprintf(h.stdout(), "%s===== end of %s =====%s\n\n", profileColor, profileName, reset)
+}
+
+// startJSONLog starts writing outputs sent to the returned channel to LogFile as JSON lines.
+// The channel is buffered so a slow disk does not stall the display; logDone is closed once
+// the channel is closed and the file flushed. Both are nil when logging is disabled or fails.
+func (h *StreamingOutputHandler) startJSONLog() (chan<- StreamingOutput, <-chan struct{}) {
+	if h.LogFile == "" {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(h.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		fmt.Fprintf(h.stdout(), "Warning: error opening log file %s: %v\n", h.LogFile, err)
+		return nil, nil
+	}
+
+	logChan := make(chan StreamingOutput, 1024)
+	logDone := make(chan struct{})
+	go func() {
+		defer close(logDone)
+		defer file.Close()
+
+		writer := bufio.NewWriter(file)
+		encoder := json.NewEncoder(writer)
+		for output := range logChan {
+			encoder.Encode(streamingLogEntry{
+				Profile:   output.ProfileName,
+				Line:      output.Line,
+				IsError:   output.IsError,
+				Timestamp: output.Timestamp,
+			})
+		}
+		if err := writer.Flush(); err != nil {
+			fmt.Fprintf(h.stdout(), "Warning: error writing log file %s: %v\n", h.LogFile, err)
+		}
+	}()
+	return logChan, logDone
+}
+
+// handleOutputControls reads profile names from stdin and toggles their output until stopped,
+// closing done once it no longer reads stdin
+func (h *StreamingOutputHandler) handleOutputControls(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	lines := utils.StdinLines()
+	for {
+		select {
+		case <-stop:
+			return
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			// A line read as the stream ended answers the prompt that follows, not the controls
+			select {
+			case <-stop:
+				utils.UnreadLine(line)
+				return
+			default:
+			}
+			line = strings.TrimSpace(line)
+			if name, found := strings.CutPrefix(line, "cancel "); found {
+				h.cancelProfile(strings.TrimSpace(name))
+				continue
+			}
+			h.toggleProfileOutput(line)
+		}
+	}
+}
+
+// cancelProfile stops a running profile through OnCancel
+func (h *StreamingOutputHandler) cancelProfile(name string) {
+	if h.OnCancel != nil && h.OnCancel(name) {
+		fmt.Fprintf(h.stdout(), ">>> Cancelling profile '%s'\n", name)
+		return
+	}
+	fmt.Fprintf(h.stdout(), ">>> Profile '%s' is not running\n", name)
+}
+
+// toggleProfileOutput hides or shows a profile's output, or shows every profile for "all"
+func (h *StreamingOutputHandler) toggleProfileOutput(name string) {
+	h.outputMutex.Lock()
+	defer h.outputMutex.Unlock()
+
+	switch {
+	case name == "":
+		return
+	case name == "all" || name == "*":
+		h.hidden = make(map[string]bool)
+		fmt.Fprintln(h.stdout(), ">>> Showing output for all profiles")
+	case !h.seen[name]:
+		fmt.Fprintf(h.stdout(), ">>> Unknown profile '%s'\n", name)
+	case h.hidden[name]:
+		delete(h.hidden, name)
+		fmt.Fprintf(h.stdout(), ">>> Showing output for profile '%s'\n", name)
+	default:
+		h.hidden[name] = true
+		fmt.Fprintf(h.stdout(), ">>> Hiding output for profile '%s'\n", name)
+	}
+}
+
+// updateProgress records a profile's state from its output and redraws the progress line on changes.
+// On a terminal the line is updated in place; otherwise every change is printed on a new line.
+func (h *StreamingOutputHandler) updateProgress(output StreamingOutput) {
+	state := "running…"
+	switch {
+	case strings.HasPrefix(output.Line, "✅ Execution"):
+		state = "✅"
+	case strings.HasPrefix(output.Line, "❌"):
+		state = "❌"
+	}
+
+	previous, known := h.states[output.ProfileName]
+	if !known {
+		h.progress = append(h.progress, output.ProfileName)
+	}
+	if previous == state {
+		return
+	}
+	h.states[output.ProfileName] = state
+	h.printProgressLine()
+}
+
+// printProgressLine redraws the quiet mode line on a terminal, or prints it on a new line otherwise
+func (h *StreamingOutputHandler) printProgressLine() {
+	parts := make([]string, 0, len(h.progress)+1)
+	if h.completed != "" {
+		parts = append(parts, h.completed+" |")
+	}
+	for _, profileName := range h.progress {
+		profileColor := h.color(h.colorManager.GetProfileColor(profileName))
+		parts = append(parts, fmt.Sprintf("%s%s%s: %s", profileColor, profileName, h.color(utils.ColorReset), h.states[profileName]))
+	}
+	line := strings.Join(parts, "  ")
+
+	if utils.StdoutIsTerminal() {
+		fmt.Fprintf(h.stdout(), "\r\033[K%s", line)
+	} else {
+		fmt.Fprintln(h.stdout(), line)
+	}
+}
+
+// isCompletionMessage checks if a line reports a profile's final outcome, which is never hidden
+func (h *StreamingOutputHandler) isCompletionMessage(line string) bool {
+	return strings.HasPrefix(line, "✅ Execution") || strings.HasPrefix(line, "❌")
+}
+
 // printStreamingLine formats and prints a single streaming output line
 func (h *StreamingOutputHandler) printStreamingLine(output StreamingOutput) {
-	timestamp := output.Timestamp.Format("15:04:05.000")
-	profileColor := h.colorManager.GetProfileColor(output.ProfileName)
+	// Without timestamps lines start directly with the profile name
+	timestamp := ""
+	if h.TimestampFormat != "" {
+		timestamp = fmt.Sprintf("[%s] ", output.Timestamp.Format(h.TimestampFormat))
+	}
+	profileColor := h.color(h.colorManager.GetProfileColor(output.ProfileName))
+	reset := h.color(utils.ColorReset)
 
 	var prefix string
 	if output.IsError {
-		prefix = fmt.Sprintf("[%s] %s%s%s %sERROR%s:",
+		prefix = fmt.Sprintf("%s%s%s%s %sERROR%s:",
 			timestamp,
-			profileColor, output.ProfileName, utils.ColorReset,
-			utils.ColorRed, utils.ColorReset)
+			profileColor, output.ProfileName, reset,
+			h.color(utils.ColorRed), reset)
 	} else {
 		// Check if this is a step message
 		line := output.Line
 		if h.isStepMessage(line) {
 			// This is a step message, color it
-			prefix = fmt.Sprintf("[%s] %s%s%s:",
+			prefix = fmt.Sprintf("%s%s%s%s:",
 				timestamp,
-				profileColor, output.ProfileName, utils.ColorReset)
-			line = fmt.Sprintf("%s%s%s", profileColor, line, utils.ColorReset)
+				profileColor, output.ProfileName, reset)
+			line = fmt.Sprintf("%s%s%s", profileColor, line, reset)
 		} else {
 			// This is regular terraform output, don't color the content
-			prefix = fmt.Sprintf("[%s] %s%s%s:",
+			prefix = fmt.Sprintf("%s%s%s%s:",
 				timestamp,
-				profileColor, output.ProfileName, utils.ColorReset)
+				profileColor, output.ProfileName, reset)
 		}
 
 		// Print each line with the profile prefix
 		lines := strings.Split(strings.TrimRight(line, "\n"), "\n")
 		for _, outputLine := range lines {
 			if strings.TrimSpace(outputLine) != "" {
-				fmt.Printf("%s %s\n", prefix, outputLine)
+				fmt.Fprintf(h.stdout(), "%s %s\n", prefix, outputLine)
 			}
 		}
 		return
@@ -80,11 +451,19 @@ func (h *StreamingOutputHandler) printStreamingLine(output StreamingOutput) {
 	lines := strings.Split(strings.TrimRight(output.Line, "\n"), "\n")
 	for _, line := range lines {
 		if strings.TrimSpace(line) != "" {
-			fmt.Printf("%s %s\n", prefix, line)
+			fmt.Fprintf(h.stdout(), "%s %s\n", prefix, line)
 		}
 	}
 }
 
+// color returns the escape code when colorized output is enabled, or an empty string otherwise
+func (h *StreamingOutputHandler) color(code string) string {
+	if !h.Colorize {
+		return ""
+	}
+	return code
+}
+
 // isStepMessage checks if a line is a step message that should be colored
 func (h *StreamingOutputHandler) isStepMessage(line string) bool {
 	stepPrefixes := []string{
@@ -102,3 +481,43 @@ func (h *StreamingOutputHandler) isStepMessage(line string) bool {
 	}
 	return false
 }
+
+// MaxLineLength is the longest output line kept whole; longer lines are split into chunks of this size
+const MaxLineLength = 1024 * 1024
+
+// scanLines calls handle for every line read from r, splitting lines longer than MaxLineLength.
+// A read error is returned after draining r so the command writing to it never blocks.
+func scanLines(r io.Reader, handle func(line string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxLineLength)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance == 0 && token == nil && err == nil && len(data) >= MaxLineLength {
+			return MaxLineLength, data[:MaxLineLength], nil
+		}
+		return advance, token, err
+	})
+
+	for scanner.Scan() {
+		handle(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		io.Copy(io.Discard, r)
+		return err
+	}
+	return nil
+}
+
+// readErrorOutput reports a failure to read a command's output stream, unless the
+// stream was closed deliberately, e.g. after a timeout
+func readErrorOutput(profileName string, err error) (StreamingOutput, bool) {
+	if err == nil || errors.Is(err, os.ErrClosed) {
+		return StreamingOutput{}, false
+	}
+	return StreamingOutput{
+		ProfileName: profileName,
+		Line:        fmt.Sprintf("Error reading output: %v", err),
+		IsError:     true,
+		Timestamp:   time.Now(),
+	}, true
+}
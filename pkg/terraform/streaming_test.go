@@ -0,0 +1,234 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamingOutputJSONLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "audit.jsonl")
+	os.WriteFile(logFile, []byte("{\"profile\":\"old\"}\n"), 0644)
+
+	handler := NewStreamingOutputHandler()
+	handler.Colorize = false
+	handler.LogFile = logFile
+
+	streamChan := make(chan StreamingOutput, 2)
+	done := make(chan bool)
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	streamChan <- StreamingOutput{ProfileName: "dev", Line: "Plan: 1 to add", Timestamp: timestamp}
+	streamChan <- StreamingOutput{ProfileName: "prod", Line: "boom", IsError: true, Timestamp: timestamp}
+	close(streamChan)
+
+	go handler.DisplayStreamingOutput(streamChan, done)
+	<-done
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Expected log file, got: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected existing line plus 2 appended lines, got: %q", lines)
+	}
+
+	var entry streamingLogEntry
+	if err := json.Unmarshal([]byte(lines[2]), &entry); err != nil {
+		t.Fatalf("Expected JSON line, got: %v", err)
+	}
+	if entry.Profile != "prod" || entry.Line != "boom" || !entry.IsError || !entry.Timestamp.Equal(timestamp) {
+		t.Errorf("Unexpected log entry: %+v", entry)
+	}
+}
+
+func TestStreamingOutputQuietProgress(t *testing.T) {
+	handler := NewStreamingOutputHandler()
+	handler.Colorize = false
+	handler.Quiet = true
+
+	outputs := []StreamingOutput{
+		{ProfileName: "dev", Line: "Starting execution..."},
+		{ProfileName: "prod", Line: "Starting execution..."},
+		{ProfileName: "dev", Line: "Plan: 1 to add"},
+		{ProfileName: "dev", Line: "✅ Execution completed successfully in 1s"},
+		{ProfileName: "prod", Line: "❌ Execution failed after 1s", IsError: true},
+	}
+	for _, output := range outputs {
+		handler.updateProgress(output)
+	}
+
+	if handler.states["dev"] != "✅" || handler.states["prod"] != "❌" {
+		t.Errorf("Expected dev to succeed and prod to fail, got: %v", handler.states)
+	}
+	if len(handler.progress) != 2 || handler.progress[0] != "dev" || handler.progress[1] != "prod" {
+		t.Errorf("Expected profiles in start order, got: %v", handler.progress)
+	}
+}
+
+func TestStreamingOutputGrouped(t *testing.T) {
+	handler := NewStreamingOutputHandler()
+	handler.Colorize = false
+	handler.Grouped = true
+
+	streamChan := make(chan StreamingOutput, 5)
+	done := make(chan bool)
+	streamChan <- StreamingOutput{ProfileName: "dev", Line: "dev line 1"}
+	streamChan <- StreamingOutput{ProfileName: "prod", Line: "prod line"}
+	streamChan <- StreamingOutput{ProfileName: "dev", Line: "dev line 2"}
+	streamChan <- StreamingOutput{ProfileName: "prod", Finished: true}
+	streamChan <- StreamingOutput{ProfileName: "dev", Finished: true}
+	close(streamChan)
+
+	output := captureStdout(t, func() {
+		go handler.DisplayStreamingOutput(streamChan, done)
+		<-done
+	})
+	order := []string{"===== prod =====", "prod line", "===== end of prod =====", "===== dev =====", "dev line 1", "dev line 2", "===== end of dev ====="}
+	position := 0
+	for _, expected := range order {
+		index := strings.Index(output[position:], expected)
+		if index < 0 {
+			t.Fatalf("Expected %q after position %d in grouped output:\n%s", expected, position, output)
+		}
+		position += index + len(expected)
+	}
+}
+
+func TestStreamingOutputGroupedBufferLimit(t *testing.T) {
+	handler := NewStreamingOutputHandler()
+	handler.Colorize = false
+	handler.Grouped = true
+	handler.BufferLimit = 12
+
+	// Profiles that never finish are flushed in name order once the stream ends
+	streamChan := make(chan StreamingOutput, 4)
+	done := make(chan bool)
+	streamChan <- StreamingOutput{ProfileName: "prod", Line: "old line"}
+	streamChan <- StreamingOutput{ProfileName: "prod", Line: "new line"}
+	streamChan <- StreamingOutput{ProfileName: "dev", Line: "dev line"}
+	close(streamChan)
+
+	output := captureStdout(t, func() {
+		go handler.DisplayStreamingOutput(streamChan, done)
+		<-done
+	})
+	if strings.Contains(output, "old line") || !strings.Contains(output, "... 1 earlier line(s) dropped") {
+		t.Errorf("Expected the oldest line dropped beyond the buffer limit, got:\n%s", output)
+	}
+	if dev, prod := strings.Index(output, "===== dev ====="), strings.Index(output, "===== prod ====="); dev < 0 || prod < dev {
+		t.Errorf("Expected remaining profiles flushed in name order, got:\n%s", output)
+	}
+}
+
+func TestStreamingOutputCompletionProgress(t *testing.T) {
+	handler := NewStreamingOutputHandler()
+	handler.Colorize = false
+	handler.ShowProgress = true
+
+	streamChan := make(chan StreamingOutput, 2)
+	done := make(chan bool)
+	// The display numbers results as they arrive, whatever index the sender had
+	streamChan <- StreamingOutput{ProfileName: "dev", Finished: true, Progress: &ProgressiveResult{
+		Result: ExecutionResult{ProfileName: "dev", Success: true}, Index: 2, Total: 2, Completed: true}}
+	streamChan <- StreamingOutput{ProfileName: "prod", Finished: true, Progress: &ProgressiveResult{
+		Result: ExecutionResult{ProfileName: "prod"}, Total: 2, Completed: true}}
+	close(streamChan)
+
+	output := captureStdout(t, func() {
+		go handler.DisplayStreamingOutput(streamChan, done)
+		<-done
+	})
+	if output != "1/2 profiles complete (dev: ✅)\n2/2 profiles complete (prod: ❌)\n" {
+		t.Errorf("Unexpected completion lines: %q", output)
+	}
+	if bar := progressBar(3, 10, 10); bar != "[███░░░░░░░]" {
+		t.Errorf("Unexpected progress bar: %s", bar)
+	}
+}
+
+func TestPrintStreamingLineTimestamps(t *testing.T) {
+	handler := NewStreamingOutputHandler()
+	handler.Colorize = false
+	output := StreamingOutput{ProfileName: "dev", Line: "Plan: 1 to add", Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	if got := captureStdout(t, func() { handler.printStreamingLine(output) }); got != "[03:04:05.000] dev: Plan: 1 to add\n" {
+		t.Errorf("Unexpected line with default timestamps: %q", got)
+	}
+
+	handler.TimestampFormat = ""
+	if got := captureStdout(t, func() { handler.printStreamingLine(output) }); got != "dev: Plan: 1 to add\n" {
+		t.Errorf("Unexpected line without timestamps: %q", got)
+	}
+}
+
+// captureStdout returns everything run prints to stdout
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	run()
+	writer.Close()
+	data, _ := io.ReadAll(reader)
+	return string(data)
+}
+
+func TestPrintHeartbeats(t *testing.T) {
+	handler := NewStreamingOutputHandler()
+	handler.Colorize = false
+	handler.TimestampFormat = ""
+	handler.Heartbeat = 30 * time.Second
+
+	now := time.Now()
+	handler.active["dev"] = &profileActivity{started: now.Add(-150 * time.Second), lastOutput: now.Add(-40 * time.Second)}
+	handler.active["prod"] = &profileActivity{started: now.Add(-60 * time.Second), lastOutput: now.Add(-5 * time.Second)}
+
+	// Only the silent profile gets a heartbeat in interleaved mode
+	if got := captureStdout(t, func() { handler.printHeartbeats(now, false) }); got != "dev: ⏳ still running (2m30s)\n" {
+		t.Errorf("Unexpected interleaved heartbeats: %q", got)
+	}
+	if got := captureStdout(t, func() { handler.printHeartbeats(now, true) }); !strings.Contains(got, "prod: ⏳ still running (1m0s)") {
+		t.Errorf("Expected every running profile in grouped mode, got: %q", got)
+	}
+}
+
+func TestExecuteCommandWithStreamingLongLines(t *testing.T) {
+	long := strings.Repeat("x", 100*1024)
+	huge := strings.Repeat("y", MaxLineLength+10)
+	input := long + "\n" + huge + "\nafter\n"
+
+	executor := &Executor{}
+	cmd := exec.Command("cat")
+	cmd.Stdin = strings.NewReader(input)
+	streamChan := make(chan StreamingOutput, 16)
+
+	result := executor.executeCommandWithStreaming(context.Background(), cmd, &ExecutionOptions{Command: "apply"},
+		ExecutionResult{ProfileName: "dev"}, time.Now(), streamChan)
+	close(streamChan)
+
+	var lines []string
+	for output := range streamChan {
+		if output.ProfileName == "dev" && !output.IsError {
+			lines = append(lines, output.Line)
+		}
+	}
+	want := []string{long, huge[:MaxLineLength], huge[MaxLineLength:], "after"}
+	if len(lines) < len(want) || lines[0] != long || lines[1] != want[1] || lines[2] != want[2] || lines[3] != "after" {
+		t.Errorf("Expected the long line whole and the oversized line split, got %d lines", len(lines))
+	}
+	if strings.Count(result.Stdout, "x") != len(long) || strings.Count(result.Stdout, "y") != len(huge) || !strings.HasSuffix(result.Stdout, "after\n") {
+		t.Errorf("Expected no output to be dropped, got %d bytes", len(result.Stdout))
+	}
+}
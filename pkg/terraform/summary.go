@@ -0,0 +1,175 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tapper/pkg/utils"
+)
+
+// summaryRow is a single profile's line in the execution summary
+type summaryRow struct {
+	profile       string
+	initStatus    PhaseStatus
+	planStatus    PhaseStatus
+	commandStatus string
+	duration      string
+	errorMessage  string
+	failed        bool
+}
+
+// PrintSummary renders a per-profile table of the init, plan and execution phase outcomes,
+// listing failed profiles last together with their error
+func PrintSummary(plan *ExecutionPlan, results []ExecutionResult, colorize bool) {
+	planResults := resultsByProfile(plan.Results)
+	execResults := resultsByProfile(results)
+
+	var rows []summaryRow
+	for _, profile := range plan.Profiles {
+		planResult, planned := planResults[profile.Name]
+		execResult, executed := execResults[profile.Name]
+
+		row := summaryRow{
+			profile:       profile.Name,
+			initStatus:    PhaseNotRun,
+			planStatus:    PhaseNotRun,
+			commandStatus: "not approved",
+		}
+		if planned {
+			row.initStatus = planResult.InitStatus
+			row.planStatus = planResult.CommandStatus
+			if !planResult.Success {
+				row.failed = true
+				row.errorMessage = resultErrorMessage(planResult)
+			}
+		}
+
+		if executed {
+			// The execution run re-initializes the workspace; report its init when the plan's succeeded
+			if row.initStatus != PhaseFailed {
+				row.initStatus = execResult.InitStatus
+			}
+			row.commandStatus = formatPhase(execResult.CommandStatus, colorize, commandColumnWidth)
+			row.duration = execResult.Duration.String()
+			if !execResult.Success {
+				row.failed = true
+				row.errorMessage = resultErrorMessage(execResult)
+			}
+		} else if !planned || row.planStatus == PhaseFailed || row.initStatus == PhaseFailed {
+			row.commandStatus = formatPhase(PhaseNotRun, colorize, commandColumnWidth)
+		} else if containsString(plan.SkippedProfiles, profile.Name) {
+			row.commandStatus = "skipped — no changes"
+		}
+		rows = append(rows, row)
+	}
+
+	// Failures go last so they are closest to the prompt
+	sort.SliceStable(rows, func(i, j int) bool {
+		return !rows[i].failed && rows[j].failed
+	})
+
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Println("=== EXECUTION SUMMARY ===")
+	fmt.Println(strings.Repeat("=", 80))
+	if plan.Ticket != "" {
+		fmt.Printf("Ticket: %s\n", plan.Ticket)
+	}
+	if plan.Reason != "" {
+		fmt.Printf("Reason: %s\n", plan.Reason)
+	}
+	fmt.Printf("%-24s %-12s %-12s %-*s %-12s %s\n", "PROFILE", "INIT", "PLAN", commandColumnWidth, strings.ToUpper(plan.Command), "DURATION", "ERROR")
+
+	for _, row := range rows {
+		fmt.Printf("%-24s %-12s %-12s %-*s %-12s %s\n",
+			row.profile,
+			formatPhase(row.initStatus, colorize, 12),
+			formatPhase(row.planStatus, colorize, 12),
+			commandColumnWidth, row.commandStatus,
+			row.duration,
+			summaryError(row.errorMessage))
+	}
+	fmt.Println()
+}
+
+// HasFailures reports whether any profile in results failed
+func HasFailures(results []ExecutionResult) bool {
+	for _, result := range results {
+		if !result.Success {
+			return true
+		}
+	}
+	return false
+}
+
+// resultErrorMessage returns a result's error, falling back to the message restored from a saved plan
+func resultErrorMessage(result ExecutionResult) string {
+	if result.Error != nil {
+		return result.Error.Error()
+	}
+	return result.ErrorMessage
+}
+
+// summaryError reduces an error message to its first line for the summary table
+func summaryError(message string) string {
+	message, _, _ = strings.Cut(strings.TrimSpace(message), "\n")
+	return message
+}
+
+// commandColumnWidth fits the widest command status, "skipped — no changes"
+const commandColumnWidth = 22
+
+// formatPhase pads and colors a phase status for a summary table column of the given width
+func formatPhase(status PhaseStatus, colorize bool, width int) string {
+	label := string(status)
+	if status == PhaseNotRun {
+		label = "-"
+	}
+
+	color := ""
+	switch status {
+	case PhaseOK, PhaseNoChanges:
+		color = utils.ColorGreen
+	case PhaseChanges:
+		color = utils.ColorYellow
+	case PhaseFailed, PhaseLocked, PhaseTimedOut, PhaseCancelled:
+		color = utils.ColorRed
+	}
+	if color == "" || !colorize {
+		return label
+	}
+
+	// Pad before coloring so escape codes do not break column alignment
+	return fmt.Sprintf("%s%-*s%s", color, width, label, utils.ColorReset)
+}
+
+// resultsByProfile indexes execution results by profile name
+func resultsByProfile(results []ExecutionResult) map[string]ExecutionResult {
+	byProfile := make(map[string]ExecutionResult, len(results))
+	for _, result := range results {
+		byProfile[result.ProfileName] = result
+	}
+	return byProfile
+}
+
+// PrintPlanOnly reports what the approved profiles would have done, for runs that stop after the review
+func PrintPlanOnly(plan *ExecutionPlan) {
+	fmt.Printf("\n=== PLAN ONLY - %s was not executed ===\n", plan.Command)
+	if len(plan.ApprovedProfiles) == 0 {
+		fmt.Println("No profiles approved.")
+		return
+	}
+
+	results := resultsByProfile(plan.Results)
+	for _, profileName := range plan.ApprovedProfiles {
+		counts, found := resultPlanCounts(results[profileName])
+		switch {
+		case !found:
+			fmt.Printf("  %s: would run %s (change counts unknown)\n", profileName, plan.Command)
+		case plan.Command == "destroy":
+			fmt.Printf("  %s: would destroy %d resource(s)\n", profileName, counts.Destroy)
+		default:
+			fmt.Printf("  %s: would add %d, change %d, destroy %d resource(s)\n", profileName, counts.Add, counts.Change, counts.Destroy)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package terraform
 
 import (
-	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -21,29 +25,140 @@ type Executor struct {
 	streamingHandler *StreamingOutputHandler
 	userInteraction  *InteractionHandler
 	workspaceManager *workspace.WorkspaceManager
-	AdditionalArgs   []string // Additional arguments to pass to terraform commands
+	out              io.Writer // Receives progress and terraform's output, os.Stdout when nil
+	authMutex        sync.Mutex
+	authRefreshed    map[string]bool // Credentials already refreshed successfully during this run
+	runningMutex     sync.Mutex
+	running          map[string]context.CancelFunc // profile name -> cancel of its running command
+	inFlight         sync.WaitGroup                // Profile commands that have not finished yet
+	interrupted      bool                          // Set by Interrupt; new commands start cancelled
+	cleanupMutex     sync.Mutex                    // Serializes WorkspaceCleanup between the interrupt handler and normal exit
+	cleanedUp        bool                          // Workspaces were removed; later cleanups are no-ops
+	AdditionalArgs   []string                      // Additional arguments to pass to terraform commands
+	Targets          []string                      // Resource addresses applied to every selected profile
+	VarFiles         []string                      // Shared var files applied to every selected profile after its own
+	Vars             []string                      // key=value variable overrides applied to every selected profile
+	OutputBufferSize int                           // Maximum bytes of output kept in memory per stream, 0 for unlimited
+	LogDir           string                        // Directory receiving the full output of every profile, if set
+	JSONPlanDir      string                        // Directory receiving each profile's plan as terraform show -json, if set
+	ValidateVars     bool                          // Check var file syntax before running terraform
+	NoChangesPolicy  string                        // What to do with profiles whose plan reports no changes
+	Reason           string                        // Free-form reason recorded with the run
+	Ticket           string                        // Change ticket ID recorded with the run
+	Reinit           bool                          // Always run terraform init, even when it looks up to date
+	InitArgs         []string                      // Extra arguments for every terraform init, e.g. -upgrade
+	DryRun           bool                          // Stop after displaying the plans, without prompting or executing
+	SummaryOnly      bool                          // Review only per-profile change counts with a single approval prompt
+	WorkspaceMapFile string                        // Write the profile-to-workspace mapping as JSON here ("-" for stdout)
+	AuthRetries      int                           // How often a command is retried after refreshing expired credentials
+	Timeout          time.Duration                 // Maximum duration of each profile's command, 0 for unlimited
+	KeepWorkspaces   bool                          // Leave workspaces in place, printing their paths, instead of cleaning up
+	GroupedOutput    bool                          // Print each profile's output as one block when it finishes, instead of interleaved
+	ConcurrencyMode  string                        // Which profiles share a concurrency group and run one at a time
+	AllowSharedState bool                          // Run even when profiles' backend configs point at the same state
 }
 
 type ExecutionOptions struct {
-	Command string
-	Args    []string
-	DryRun  bool
+	Command     string
+	Args        []string
+	DryRun      bool
+	CaptureOnly bool              // Capture stdout without streaming it to the terminal
+	SavePlan    bool              // Write the plan to PlanFileName in the workspace
+	PlanFiles   map[string]string // Saved plan file per profile to apply instead of re-planning
+	Targets     []string          // Resource addresses to limit the command to
+	VarFiles    []string          // Shared var files, layered after the profile's var file
+	Vars        []string          // key=value variable overrides, taking precedence over the var file
+	Ordered     bool              // Respect profile dependencies (reversed for destroy)
+	JSONPlanDir string            // Write the plan as terraform show -json to this directory and tally its changes
 }
 
 const PREVIEW_COMMAND = "plan"
 
+// DefaultJSONPlanDir receives the JSON plans when --json-plan is given without a directory
+const DefaultJSONPlanDir = ".tapper/json-plans"
+
+// DefaultMaxConcurrency is the default number of profiles executed concurrently
+const DefaultMaxConcurrency = 5
+
+// DefaultAuthRetries is how often a command is retried after refreshing expired credentials
+const DefaultAuthRetries = 1
+
+// INIT_COMMAND runs only the per-workspace init step
+const INIT_COMMAND = "init"
+
+// FORCE_UNLOCK_COMMAND releases a stuck state lock of a single profile
+const FORCE_UNLOCK_COMMAND = "force-unlock"
+
+// CONSOLE_COMMAND opens an interactive terraform console in a single profile
+const CONSOLE_COMMAND = "console"
+
+// GRAPH_COMMAND prints a profile's resource dependency graph in DOT format
+const GRAPH_COMMAND = "graph"
+
+// IMPORT_COMMAND brings an existing resource under management in a single profile
+const IMPORT_COMMAND = "import"
+
+// OUTPUT_COMMAND reads the root module outputs of a profile
+const OUTPUT_COMMAND = "output"
+
+// REFRESH_COMMAND updates state to match the real infrastructure, without other changes.
+// It is previewed with plan -refresh-only and executed as apply -refresh-only.
+const REFRESH_COMMAND = "refresh"
+
+// SHOW_COMMAND displays a profile's current state or a saved plan
+const SHOW_COMMAND = "show"
+
+// STATE_COMMAND inspects a profile's state, e.g. "state list"
+const STATE_COMMAND = "state"
+
+// VALIDATE_COMMAND checks a profile's configuration without accessing remote state
+const VALIDATE_COMMAND = "validate"
+
+// Policies for profiles whose plan reports no changes
+const (
+	NoChangesApply = "apply" // Review and execute the profile as usual
+	NoChangesSkip  = "skip"  // Leave the profile out of review and execution
+	NoChangesFail  = "fail"  // Abort the run
+)
+
 // NewExecutor creates a new parallel executor
 func NewExecutor() (*Executor, error) {
-	wm, err := workspace.NewWorkspaceManager()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	wm, err := workspace.NewWorkspaceManager(cwd)
 	if err != nil {
 		return nil, fmt.Errorf("error creating workspace manager: %w", err)
 	}
-	return &Executor{
-		MaxConcurrency:   5, // Default to 5 concurrent executions
+	executor := &Executor{
+		MaxConcurrency:   DefaultMaxConcurrency,
+		NoChangesPolicy:  NoChangesSkip,
+		ConcurrencyMode:  ConcurrencyGroupsManifest,
+		AuthRetries:      DefaultAuthRetries,
 		streamingHandler: NewStreamingOutputHandler(),
 		userInteraction:  NewInteractionHandler(),
 		workspaceManager: wm,
-	}, nil
+		running:          make(map[string]context.CancelFunc),
+	}
+	executor.streamingHandler.OnCancel = executor.CancelProfile
+	return executor, nil
+}
+
+// SetProgressWriter sends progress, terraform's streamed output and review prompts to w instead of stdout,
+// keeping stdout for a command's result, e.g. a JSON document
+func (e *Executor) SetProgressWriter(w io.Writer) {
+	e.out = w
+	e.streamingHandler.out = w
+	e.userInteraction.out = w
+}
+
+// stdout returns the writer receiving progress and terraform's output
+func (e *Executor) stdout() io.Writer {
+	if e.out == nil {
+		return os.Stdout
+	}
+	return e.out
 }
 
 // SetAdditionalArgs sets additional arguments to be passed to terraform commands
@@ -52,61 +167,526 @@ func (e *Executor) SetAdditionalArgs(args []string) error {
 	return nil
 }
 
+// SetTargets sets the resource addresses every command is limited to
+func (e *Executor) SetTargets(targets []string) error {
+	for _, target := range targets {
+		if strings.TrimSpace(target) == "" {
+			return fmt.Errorf("target address must not be empty")
+		}
+	}
+	e.Targets = targets
+	return nil
+}
+
+// SetVarFiles sets the shared var files passed to every selected profile after its own.
+// Paths are made absolute because terraform runs inside the profile workspaces.
+func (e *Executor) SetVarFiles(varFiles []string) error {
+	absFiles := make([]string, 0, len(varFiles))
+	for _, varFile := range varFiles {
+		exists, err := utils.CheckFileOrDirExists(varFile)
+		if err != nil {
+			return fmt.Errorf("error checking var file: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("var file not found: %s", varFile)
+		}
+		absFile, err := filepath.Abs(varFile)
+		if err != nil {
+			return fmt.Errorf("error resolving var file %s: %w", varFile, err)
+		}
+		absFiles = append(absFiles, absFile)
+	}
+	e.VarFiles = absFiles
+	return nil
+}
+
+// SetVars sets the key=value variable overrides passed to every selected profile
+func (e *Executor) SetVars(vars []string) error {
+	for _, v := range vars {
+		key, _, found := strings.Cut(v, "=")
+		if !found || !varNamePattern.MatchString(key) {
+			return fmt.Errorf("invalid variable %q: expected key=value with a valid variable name", v)
+		}
+	}
+	e.Vars = vars
+	return nil
+}
+
+// varNamePattern matches valid terraform variable names
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// SetInitArgs sets extra arguments for terraform init. Backend options go to each workspace's
+// init and the rest (e.g. -upgrade) to the module install; either always runs when given
+// arguments, since they usually ask for work a previous init did not do.
+func (e *Executor) SetInitArgs(args []string) error {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("invalid init argument %q: expected a flag such as -upgrade", arg)
+		}
+	}
+	e.InitArgs = args
+	return nil
+}
+
+// SetMaxConcurrency sets how many profiles may execute at the same time
+func (e *Executor) SetMaxConcurrency(maxConcurrency int) error {
+	if maxConcurrency < 1 {
+		return fmt.Errorf("invalid parallelism %d: at least 1 profile must run at a time", maxConcurrency)
+	}
+	e.MaxConcurrency = maxConcurrency
+	return nil
+}
+
+// SetWorkspaceMode selects whether workspaces symlink or copy the module files
+func (e *Executor) SetWorkspaceMode(mode string) error {
+	return e.workspaceManager.SetMode(mode)
+}
+
+// SetIsolatedDirs mirrors the given module directories with per-file symlinks in every workspace,
+// so terraform writes inside them do not leak between profiles
+func (e *Executor) SetIsolatedDirs(dirs []string) {
+	e.workspaceManager.IsolatedDirs = dirs
+}
+
+// AddWorkspaceIgnore leaves top-level module entries matching patterns out of the workspaces
+func (e *Executor) AddWorkspaceIgnore(patterns []string) error {
+	return e.workspaceManager.AddIgnorePatterns(patterns)
+}
+
+// SetWorkspaceParent places profile workspaces in dir instead of next to the module
+func (e *Executor) SetWorkspaceParent(dir string) error {
+	return e.workspaceManager.SetWorkspaceParent(dir)
+}
+
+// SetColor enables or disables ANSI colors in tapper's and terraform's output
+func (e *Executor) SetColor(colorize bool) {
+	e.streamingHandler.Colorize = colorize
+}
+
+// SetColorPalette selects the palette profile names are colored with, e.g. colorblind
+func (e *Executor) SetColorPalette(palette string) error {
+	return e.streamingHandler.colorManager.SetPalette(palette)
+}
+
+// Colorize reports whether colored output is enabled
+func (e *Executor) Colorize() bool {
+	return e.streamingHandler.Colorize
+}
+
+// SetProgress shows how many of the run's profiles have completed as each one finishes
+func (e *Executor) SetProgress(progress bool) {
+	e.streamingHandler.ShowProgress = progress
+}
+
+// SetQuiet replaces the streamed terraform output with a per-profile progress line
+func (e *Executor) SetQuiet(quiet bool) {
+	e.streamingHandler.Quiet = quiet
+}
+
+// SetHeartbeat sets how often silent running profiles print a "still running" line; 0 disables it
+func (e *Executor) SetHeartbeat(interval time.Duration) {
+	e.streamingHandler.Heartbeat = interval
+}
+
+// SetTimestampFormat sets the Go time layout of streamed line timestamps; empty omits them
+func (e *Executor) SetTimestampFormat(format string) {
+	e.streamingHandler.TimestampFormat = format
+}
+
+// SetLogFile writes every streamed output line as JSON to path, in addition to the terminal
+func (e *Executor) SetLogFile(path string) {
+	e.streamingHandler.LogFile = path
+}
+
+// SetAutoApprove approves every successfully planned profile without prompting
+func (e *Executor) SetAutoApprove(autoApprove bool) {
+	e.userInteraction.AutoApprove = autoApprove
+}
+
+// SetReviewPageSize reviews profiles in pages of size with one approval per page
+func (e *Executor) SetReviewPageSize(size int) error {
+	if size < 0 {
+		return fmt.Errorf("review page size must not be negative, got %d", size)
+	}
+	e.userInteraction.PageSize = size
+	return nil
+}
+
+// SetPager opens each profile's output in a pager during plan review
+func (e *Executor) SetPager(pager bool) {
+	e.userInteraction.Pager = pager
+}
+
+// SetInteractiveOutput enables toggling profile output from stdin while streaming
+func (e *Executor) SetInteractiveOutput(interactive bool) {
+	e.streamingHandler.Interactive = interactive
+}
+
+// SetNoChangesPolicy sets the policy applied to profiles whose plan reports no changes
+func (e *Executor) SetNoChangesPolicy(policy string) error {
+	switch policy {
+	case NoChangesApply, NoChangesSkip, NoChangesFail:
+		e.NoChangesPolicy = policy
+		return nil
+	default:
+		return fmt.Errorf("invalid no-changes policy %q: must be one of %s, %s, %s", policy, NoChangesSkip, NoChangesApply, NoChangesFail)
+	}
+}
+
+// SetConcurrencyGroups sets how profiles that must not run at the same time are grouped
+func (e *Executor) SetConcurrencyGroups(mode string) error {
+	switch mode {
+	case ConcurrencyGroupsManifest, ConcurrencyGroupsBackend, ConcurrencyGroupsNone:
+		e.ConcurrencyMode = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid concurrency group mode %q: must be one of %s, %s, %s", mode, ConcurrencyGroupsManifest, ConcurrencyGroupsBackend, ConcurrencyGroupsNone)
+	}
+}
+
 // PlanExecution creates an execution plan by running the corresponding command in dry-run mode
 func (e *Executor) PlanExecution(command string, profiles []Profile) (*ExecutionPlan, error) {
 	if len(profiles) == 0 {
 		return nil, fmt.Errorf("no profiles provided")
 	}
 
-	if err := e.Init(profiles[0]); err != nil {
-		return nil, fmt.Errorf("error running terraform init: %w", err)
+	if e.ValidateVars {
+		if err := ValidateVarFiles(profiles); err != nil {
+			return nil, fmt.Errorf("invalid var files:\n%w", err)
+		}
 	}
 
-	// Create workspaces
-	workspaceProfiles := make([]workspace.Profile, len(profiles))
-	for i, profile := range profiles {
-		workspaceProfiles[i] = workspace.Profile{Name: profile.Name}
+	// Two profiles writing one state is usually a copy-pasted backend config
+	if err := CheckStateCollisions(profiles); err != nil {
+		if !e.AllowSharedState {
+			return nil, fmt.Errorf("profiles would overwrite each other's state (use --force to run anyway):\n%w", err)
+		}
+		fmt.Fprintf(e.stdout(), "⚠️  WARNING: running despite shared states because of --force; these profiles overwrite each other's state:\n%v\n\n", err)
 	}
-	if err := e.workspaceManager.CreateWorkspaces(workspaceProfiles); err != nil {
-		return nil, fmt.Errorf("error creating workspaces: %w", err)
+
+	if err := e.prepareWorkspaces(profiles); err != nil {
+		return nil, err
 	}
 
 	plan := &ExecutionPlan{
 		Command:  command,
 		Profiles: profiles,
 		Results:  make([]ExecutionResult, 0, len(profiles)),
+		Reason:   e.Reason,
+		Ticket:   e.Ticket,
 	}
 
-	fmt.Printf("\n=== Streaming Execution for %s ===\n", command)
-	fmt.Printf("Executing %d profiles with real-time output...\n\n", len(profiles))
+	fmt.Fprintf(e.stdout(), "\n=== Streaming Execution for %s ===\n", command)
+	fmt.Fprintf(e.stdout(), "Executing %d profiles with real-time output...\n\n", len(profiles))
+
+	executionOptions := &ExecutionOptions{
+		Command:  PREVIEW_COMMAND,
+		Args:     e.previewArgs(command),
+		DryRun:   true,
+		SavePlan: command == "apply" || command == "destroy" || command == REFRESH_COMMAND,
+		Targets:  e.Targets,
+		VarFiles: e.VarFiles,
+		Vars:     e.Vars,
+		// Summary-only reviews skip the full plan output, which stays available in the logs
+		CaptureOnly: e.SummaryOnly,
+		JSONPlanDir: e.JSONPlanDir,
+	}
+
+	results, err := e.parallelExecution(profiles, executionOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Results = results
+	if err := e.applyNoChangesPolicy(plan); err != nil {
+		return nil, err
+	}
+
+	// Dry runs only display the plans and never prompt for approval
+	if e.DryRun {
+		fmt.Fprintf(e.stdout(), "\n"+strings.Repeat("=", 80)+"\n")
+		fmt.Fprintf(e.stdout(), "=== DRY RUN - PLAN REVIEW ===\n")
+		fmt.Fprintf(e.stdout(), strings.Repeat("=", 80)+"\n\n")
+		e.userInteraction.DisplayResults(plan.Results)
+		return plan, nil
+	}
+
+	// The workspaces are deleted on exit, so a resumed review applies copies of the plans
+	if command == "apply" || command == "destroy" || command == REFRESH_COMMAND {
+		if err := preserveReviewPlans(plan); err != nil {
+			return nil, err
+		}
+	}
+	if err := SavePlanState(plan, PlanStateFile); err != nil {
+		return nil, err
+	}
+
+	return e.reviewPlan(plan)
+}
 
-	previewArgs := []string{"--detailed-exitcode"}
+// previewArgs returns the plan arguments previewing command.
+// The command builder adds --detailed-exitcode to plans unless the user overrides it.
+func (e *Executor) previewArgs(command string) []string {
+	var args []string
 
 	// Emulate destruction with command (otherwise plain plan will show)
 	if command == "destroy" {
-		previewArgs = append(previewArgs, "--destroy")
+		args = append(args, "--destroy")
+	}
+	// Refreshes preview the drift they would record in state
+	if command == REFRESH_COMMAND {
+		args = append(args, "-refresh-only")
 	}
 
 	// Add additional arguments to preview args
-	previewArgs = append(previewArgs, e.AdditionalArgs...)
+	return append(args, e.AdditionalArgs...)
+}
 
-	executionOptions := &ExecutionOptions{
-		Command: PREVIEW_COMMAND,
-		Args:    previewArgs,
-		DryRun:  true,
+// replanTargeted plans only the selected resources of a reviewed profile into a new saved plan
+// and returns its path, so a partial approval applies exactly the plan shown for it
+func (e *Executor) replanTargeted(plan *ExecutionPlan, profileName string, targets []string) (string, error) {
+	profiles := e.filterApprovedProfiles(plan.Profiles, []string{profileName})
+	if len(profiles) == 0 {
+		return "", fmt.Errorf("profile %s is not part of the plan", profileName)
+	}
+	workspacePath, exists := e.workspaceManager.GetWorkspacePath(profileName)
+	if !exists {
+		return "", fmt.Errorf("workspace path not found for profile %s", profileName)
 	}
 
-	results, err := e.parallelExecution(profiles, executionOptions)
+	if err := os.MkdirAll(ReviewPlansDir, 0700); err != nil {
+		return "", fmt.Errorf("error creating plan directory %s: %w", ReviewPlansDir, err)
+	}
+	planFile, err := filepath.Abs(filepath.Join(ReviewPlansDir, utils.FileSafeName(profileName)+".targeted.tfplan"))
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := e.commandContext(profileName)
+	defer cancel()
+	cmd, err := NewCommandBuilder().WithNoColor(!e.Colorize()).WithContext(ctx).BuildCommandFromProfile(profiles[0], workspacePath, &ExecutionOptions{
+		Command:  PREVIEW_COMMAND,
+		Args:     append(e.previewArgs(plan.Command), fmt.Sprintf("-out=%s", planFile)),
+		DryRun:   true,
+		Targets:  targets,
+		VarFiles: e.VarFiles,
+		Vars:     e.Vars,
+	})
+	if err != nil {
+		return "", fmt.Errorf("command build failed: %w", err)
+	}
+	cmd.Stdout = e.stdout()
+	cmd.Stderr = os.Stderr
+
+	// --detailed-exitcode reports pending changes with exit code 2
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 2) {
+		return "", fmt.Errorf("error planning selected resources: %w", err)
+	}
+	return planFile, nil
+}
+
+// applyNoChangesPolicy handles profiles whose plan succeeded without any changes
+func (e *Executor) applyNoChangesPolicy(plan *ExecutionPlan) error {
+	var unchanged []string
+	for _, result := range plan.Results {
+		if result.Success && result.ChangesKnown && !result.HasChanges {
+			unchanged = append(unchanged, result.ProfileName)
+		}
+	}
+	if len(unchanged) == 0 {
+		return nil
+	}
+
+	switch e.NoChangesPolicy {
+	case NoChangesSkip:
+		for _, profileName := range unchanged {
+			fmt.Fprintf(e.stdout(), "Skipping profile '%s': no changes\n", profileName)
+		}
+		plan.ReviewedProfiles = append(plan.ReviewedProfiles, unchanged...)
+		plan.SkippedProfiles = append(plan.SkippedProfiles, unchanged...)
+	case NoChangesFail:
+		return fmt.Errorf("no changes detected for profile(s): %s", strings.Join(unchanged, ", "))
+	}
+	return nil
+}
+
+// ResumeExecution continues the review of a plan persisted by an interrupted run
+func (e *Executor) ResumeExecution(command string) (*ExecutionPlan, error) {
+	plan, err := LoadPlanState(PlanStateFile)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Command != command {
+		return nil, fmt.Errorf("saved plan is for command %s, not %s", plan.Command, command)
+	}
+
+	// Keep the annotations of the original run unless new ones were given
+	if e.Reason == "" {
+		e.Reason = plan.Reason
+	}
+	if e.Ticket == "" {
+		e.Ticket = plan.Ticket
+	}
+	plan.Reason, plan.Ticket = e.Reason, e.Ticket
+
+	if err := checkReviewPlans(plan); err != nil {
+		return nil, err
+	}
+	if err := e.prepareWorkspaces(plan.Profiles); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(e.stdout(), "Resuming review: %d of %d profile(s) already reviewed\n", len(plan.ReviewedProfiles), len(plan.Results))
+	return e.reviewPlan(plan)
+}
+
+// ApplySavedPlan loads a plan saved with SaveApprovedPlan for command and prepares its approved
+// profiles for ExecutePlan. Profiles are taken from the current configuration and must still exist.
+func (e *Executor) ApplySavedPlan(path, command string, cfg *Config) (*ExecutionPlan, error) {
+	plan, err := LoadApprovedPlan(path)
 	if err != nil {
 		return nil, err
 	}
+	if plan.Command != command {
+		return nil, fmt.Errorf("saved plan is for command %s, not %s", plan.Command, command)
+	}
+	if len(plan.ApprovedProfiles) == 0 {
+		return nil, fmt.Errorf("saved plan %s has no approved profiles", path)
+	}
+
+	var errs []error
+	var profiles []Profile
+	for _, profileName := range plan.ApprovedProfiles {
+		profile, exists := GetProfile(cfg, profileName)
+		if !exists {
+			errs = append(errs, fmt.Errorf("profile '%s' no longer exists", profileName))
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("saved plan %s does not match the profiles on disk:\n%w", path, err)
+	}
+	plan.Profiles = profiles
+
+	if e.Reason == "" {
+		e.Reason = plan.Reason
+	}
+	if e.Ticket == "" {
+		e.Ticket = plan.Ticket
+	}
+
+	if err := e.prepareWorkspaces(profiles); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// prepareWorkspaces initializes terraform and creates a workspace for every profile
+func (e *Executor) prepareWorkspaces(profiles []Profile) error {
+	if e.isInterrupted() {
+		return errInterrupted
+	}
 
-	// Display review and get approval
-	fmt.Printf("\n" + strings.Repeat("=", 80) + "\n")
-	fmt.Printf("=== EXECUTION COMPLETED - PLAN REVIEW ===\n")
-	fmt.Printf(strings.Repeat("=", 80) + "\n\n")
+	// Every init of the run shares one provider cache, created before the first init
+	if _, err := e.workspaceManager.CreatePluginCache(); err != nil {
+		return err
+	}
 
-	approvedProfiles, err := e.userInteraction.ReviewAndApproveResults(results)
+	// Each module involved is checked and installed once, with the first of its profiles
+	installed := make(map[string]bool)
+	for _, profile := range profiles {
+		if installed[profile.ModuleDir] {
+			continue
+		}
+		installed[profile.ModuleDir] = true
+		if e.isInterrupted() {
+			return errInterrupted
+		}
+
+		e.warnIfLocalState(profile.ModuleDir)
+		if err := e.InstallDependencies(profile); err != nil {
+			return fmt.Errorf("error installing providers and modules: %w", err)
+		}
+	}
+
+	workspaceProfiles := make([]workspace.Profile, len(profiles))
+	for i, profile := range profiles {
+		workspaceProfiles[i] = workspace.Profile{Name: profile.Name, Dir: profile.ModuleDir}
+	}
+	if e.isInterrupted() {
+		return errInterrupted
+	}
+	if err := e.workspaceManager.CreateWorkspaces(workspaceProfiles, e.MaxConcurrency); err != nil {
+		return fmt.Errorf("error creating workspaces: %w", err)
+	}
+	return e.writeWorkspaceMap()
+}
+
+// writeWorkspaceMap emits the profile-to-workspace mapping when WorkspaceMapFile is set
+func (e *Executor) writeWorkspaceMap() error {
+	if e.WorkspaceMapFile == "" {
+		return nil
+	}
+	if e.WorkspaceMapFile == "-" {
+		return e.workspaceManager.WriteMapping(os.Stdout)
+	}
+
+	file, err := os.Create(e.WorkspaceMapFile)
+	if err != nil {
+		return fmt.Errorf("error creating workspace map file: %w", err)
+	}
+	defer file.Close()
+
+	return e.workspaceManager.WriteMapping(file)
+}
+
+// warnIfLocalState warns when the module has no backend, since local state written inside
+// profile workspaces is lost when the workspaces are cleaned up
+func (e *Executor) warnIfLocalState(moduleDir string) {
+	if moduleDir == "" {
+		moduleDir = "."
+	}
+	hasBackend, err := utils.HasBackendBlock(moduleDir)
+	if err != nil || hasBackend {
+		return
+	}
+
+	fmt.Fprintln(e.stdout(), strings.Repeat("!", 80))
+	if moduleDir == "." {
+		fmt.Fprintln(e.stdout(), "WARNING: no backend is configured in this module.")
+	} else {
+		fmt.Fprintf(e.stdout(), "WARNING: no backend is configured in module %s.\n", moduleDir)
+	}
+	fmt.Fprintln(e.stdout(), "Each profile runs in a temporary workspace, so local terraform.tfstate files written")
+	fmt.Fprintln(e.stdout(), "there are DELETED on cleanup and any changes applied will be lost from state.")
+	fmt.Fprintln(e.stdout(), "Configure a backend (e.g. backend \"s3\" {} in a terraform block) before applying;")
+	fmt.Fprintln(e.stdout(), "--keep-workspaces only preserves the state files for recovery, later runs do not use them.")
+	fmt.Fprintln(e.stdout(), strings.Repeat("!", 80))
+}
+
+// reviewPlan displays the plan results and records the user's approvals
+func (e *Executor) reviewPlan(plan *ExecutionPlan) (*ExecutionPlan, error) {
+	fmt.Fprintf(e.stdout(), "\n"+strings.Repeat("=", 80)+"\n")
+	fmt.Fprintf(e.stdout(), "=== EXECUTION COMPLETED - PLAN REVIEW ===\n")
+	if suffix := e.annotationSuffix(); suffix != "" {
+		fmt.Fprintf(e.stdout(), "Run annotations:%s\n", suffix)
+	}
+	fmt.Fprintf(e.stdout(), strings.Repeat("=", 80)+"\n\n")
+
+	persist := func(p *ExecutionPlan) error {
+		return SavePlanState(p, PlanStateFile)
+	}
+	e.userInteraction.Replan = func(profileName string, targets []string) (string, error) {
+		return e.replanTargeted(plan, profileName, targets)
+	}
+	review := e.userInteraction.ReviewAndApproveResults
+	if e.SummaryOnly {
+		review = e.userInteraction.ReviewSummary
+	}
+	approvedProfiles, err := review(plan, persist)
 	if err != nil {
 		return nil, fmt.Errorf("error during streaming execution: %w", err)
 	}
@@ -115,14 +695,241 @@ func (e *Executor) PlanExecution(command string, profiles []Profile) (*Execution
 	return plan, nil
 }
 
+// InitProfiles prepares a workspace for every profile and runs only terraform init in each
+func (e *Executor) InitProfiles(profiles []Profile) ([]ExecutionResult, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles provided")
+	}
+
+	if err := e.prepareWorkspaces(profiles); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(e.stdout(), "Initializing %d profiles with real-time output...\n\n", len(profiles))
+	return e.parallelExecution(profiles, &ExecutionOptions{Command: INIT_COMMAND})
+}
+
+// Output initializes every profile's workspace and collects its terraform outputs as JSON
+func (e *Executor) Output(profiles []Profile) ([]ExecutionResult, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles provided")
+	}
+
+	if err := e.prepareWorkspaces(profiles); err != nil {
+		return nil, err
+	}
+
+	results, err := e.parallelExecution(profiles, &ExecutionOptions{
+		Command:     OUTPUT_COMMAND,
+		Args:        []string{"-json"},
+		CaptureOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, resultsError(results)
+}
+
+// StateList initializes every profile's workspace and captures terraform state list from each
+func (e *Executor) StateList(profiles []Profile) ([]ExecutionResult, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles provided")
+	}
+
+	if err := e.prepareWorkspaces(profiles); err != nil {
+		return nil, err
+	}
+
+	results, err := e.parallelExecution(profiles, &ExecutionOptions{
+		Command:     STATE_COMMAND,
+		Args:        []string{"list"},
+		CaptureOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, resultsError(results)
+}
+
+// Validate initializes every profile's workspace and runs terraform validate in each
+func (e *Executor) Validate(profiles []Profile) ([]ExecutionResult, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles provided")
+	}
+
+	if err := e.prepareWorkspaces(profiles); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(e.stdout(), "Validating %d profiles with real-time output...\n\n", len(profiles))
+	results, err := e.parallelExecution(profiles, &ExecutionOptions{Command: VALIDATE_COMMAND})
+	if err != nil {
+		return nil, err
+	}
+	return results, resultsError(results)
+}
+
+// Passthrough runs command for a single profile without the plan review, like a thin terraform wrapper.
+// Commands that change infrastructure or state run with -auto-approve, so they require AutoApprove.
+func (e *Executor) Passthrough(command string, profile Profile) (ExecutionResult, error) {
+	switch command {
+	case "apply", "destroy", REFRESH_COMMAND:
+		if !e.userInteraction.AutoApprove {
+			return ExecutionResult{}, fmt.Errorf("%s --passthrough skips the review and confirmation, so it requires --auto-approve", command)
+		}
+	}
+
+	if err := e.prepareWorkspaces([]Profile{profile}); err != nil {
+		return ExecutionResult{}, err
+	}
+
+	results, err := e.parallelExecution([]Profile{profile}, &ExecutionOptions{
+		Command:  command,
+		Args:     e.AdditionalArgs,
+		Targets:  e.Targets,
+		VarFiles: e.VarFiles,
+		Vars:     e.Vars,
+	})
+	if err != nil {
+		return ExecutionResult{}, err
+	}
+	return results[0], nil
+}
+
+// Import runs terraform import for a single resource in one profile's workspace
+func (e *Executor) Import(profile Profile, address, id string) (ExecutionResult, error) {
+	if strings.TrimSpace(address) == "" || strings.TrimSpace(id) == "" {
+		return ExecutionResult{}, fmt.Errorf("import requires a non-empty resource address and id")
+	}
+
+	return e.runInWorkspace(profile, func(cmdBuilder *CommandBuilder, workspacePath string) (*exec.Cmd, error) {
+		return cmdBuilder.WithExtraVarFiles(e.VarFiles).WithVars(e.Vars).BuildImportCommand(profile, workspacePath, address, id)
+	})
+}
+
+// ForceUnlock runs terraform force-unlock for lockID against one profile's backend
+func (e *Executor) ForceUnlock(profile Profile, lockID string) (ExecutionResult, error) {
+	if strings.TrimSpace(lockID) == "" {
+		return ExecutionResult{}, fmt.Errorf("force-unlock requires a non-empty lock ID")
+	}
+
+	return e.runInWorkspace(profile, func(cmdBuilder *CommandBuilder, workspacePath string) (*exec.Cmd, error) {
+		return cmdBuilder.BuildForceUnlockCommand(profile, workspacePath, lockID), nil
+	})
+}
+
+// Console runs an interactive terraform console in one profile's initialized workspace
+func (e *Executor) Console(profile Profile) (ExecutionResult, error) {
+	return e.runInWorkspace(profile, func(cmdBuilder *CommandBuilder, workspacePath string) (*exec.Cmd, error) {
+		return cmdBuilder.BuildConsoleCommand(profile, workspacePath)
+	})
+}
+
+// Graph writes the DOT dependency graph of a profile's configuration to out
+func (e *Executor) Graph(profile Profile, out io.Writer) (ExecutionResult, error) {
+	return e.runInWorkspace(profile, func(cmdBuilder *CommandBuilder, workspacePath string) (*exec.Cmd, error) {
+		cmd := cmdBuilder.BuildGraphCommand(profile, workspacePath)
+		cmd.Stdout = out
+		return cmd, nil
+	})
+}
+
+// Show writes a profile's current state, or the saved plan in planFile if set, to out
+func (e *Executor) Show(profile Profile, planFile string, asJSON bool, out io.Writer) (ExecutionResult, error) {
+	return e.runInWorkspace(profile, func(cmdBuilder *CommandBuilder, workspacePath string) (*exec.Cmd, error) {
+		cmd := cmdBuilder.BuildShowCommand(profile, workspacePath, planFile, asJSON)
+		cmd.Stdout = out
+		return cmd, nil
+	})
+}
+
+// runInWorkspace initializes a single profile's workspace and runs the command built for it.
+// Only one profile runs, so init and the command print directly instead of using the parallel
+// streaming path.
+func (e *Executor) runInWorkspace(profile Profile, build func(cmdBuilder *CommandBuilder, workspacePath string) (*exec.Cmd, error)) (ExecutionResult, error) {
+	if err := e.prepareWorkspaces([]Profile{profile}); err != nil {
+		return ExecutionResult{}, err
+	}
+	workspacePath, exists := e.workspaceManager.GetWorkspacePath(profile.Name)
+	if !exists {
+		return ExecutionResult{}, fmt.Errorf("workspace path not found for profile %s", profile.Name)
+	}
+
+	startTime := time.Now()
+	result := ExecutionResult{
+		ProfileName: profile.Name,
+		WorkingDir:  workspacePath,
+	}
+
+	initOutput := make(chan StreamingOutput)
+	printed := make(chan struct{})
+	go func() {
+		for output := range initOutput {
+			fmt.Fprintln(e.stdout(), output.Line)
+		}
+		close(printed)
+	}()
+	initStatus, err := e.initInWorkspaceWithStreaming(profile, workspacePath, initOutput)
+	close(initOutput)
+	<-printed
+	result.InitStatus = initStatus
+	if err != nil {
+		result.Error = fmt.Errorf("terraform init failed: %w", err)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	ctx, cancel := e.commandContext(profile.Name)
+	defer cancel()
+	cmd, err := build(NewCommandBuilder().WithNoColor(!e.Colorize()).WithContext(ctx), workspacePath)
+	if err != nil {
+		result.Error = fmt.Errorf("command build failed: %w", err)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+	cmd.Stdin = os.Stdin
+	if cmd.Stdout == nil {
+		cmd.Stdout = e.stdout()
+	}
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	result.Duration = time.Since(startTime)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	}
+	if err != nil {
+		result.Error = fmt.Errorf("terraform %s failed: %w", cmd.Args[1], err)
+		result.CommandStatus = PhaseFailed
+		return result, nil
+	}
+
+	result.Success = true
+	result.CommandStatus = PhaseOK
+	return result, nil
+}
+
 // ExecutePlan executes the approved execution plan
 func (e *Executor) ExecutePlan(plan *ExecutionPlan) ([]ExecutionResult, error) {
 	approvedProfileStructs := e.filterApprovedProfiles(plan.Profiles, plan.ApprovedProfiles)
-	fmt.Printf("Executing %d profiles with real-time output...\n\n", len(approvedProfileStructs))
+	fmt.Fprintf(e.stdout(), "Executing %d profiles with real-time output...\n\n", len(approvedProfileStructs))
 	execOpts := &ExecutionOptions{
-		Command: plan.Command,
-		Args:    e.AdditionalArgs, // Include additional arguments
-		DryRun:  false,
+		Command:  plan.Command,
+		Args:     e.AdditionalArgs, // Include additional arguments
+		DryRun:   false,
+		Targets:  e.Targets,
+		VarFiles: e.VarFiles,
+		Vars:     e.Vars,
+		Ordered:  true,
+	}
+
+	// Apply exactly the reviewed plans for commands that change infrastructure
+	if plan.Command == "apply" || plan.Command == "destroy" || plan.Command == REFRESH_COMMAND {
+		execOpts.PlanFiles = make(map[string]string)
+		for _, result := range plan.Results {
+			execOpts.PlanFiles[result.ProfileName] = result.PlanFile
+		}
 	}
 
 	results, err := e.parallelExecution(approvedProfileStructs, execOpts)
@@ -130,25 +937,68 @@ func (e *Executor) ExecutePlan(plan *ExecutionPlan) ([]ExecutionResult, error) {
 		return nil, err
 	}
 
-	fmt.Println() // Add a blank line for clean separation
-	return results, nil
+	fmt.Fprintln(e.stdout()) // Add a blank line for clean separation
+	return results, resultsError(results)
+}
+
+// resultsError joins the errors of all failed results, ordered by profile name
+func resultsError(results []ExecutionResult) error {
+	sorted := make([]ExecutionResult, len(results))
+	copy(sorted, results)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ProfileName < sorted[j].ProfileName
+	})
+
+	var errs []error
+	for _, result := range sorted {
+		if result.Success {
+			continue
+		}
+		err := result.Error
+		if err == nil {
+			err = errors.New("execution failed")
+		}
+		errs = append(errs, fmt.Errorf("profile %s: %w", result.ProfileName, err))
+	}
+	return errors.Join(errs...)
 }
 
 // parallelExecution prepares the environment for parallel streaming
 func (e *Executor) parallelExecution(profiles []Profile, execOpts *ExecutionOptions) ([]ExecutionResult, error) {
-	fmt.Printf("EXECUTING COMMAND %s\n", execOpts.Command)
+	fmt.Fprintf(e.stdout(), "EXECUTING COMMAND %s\n", execOpts.Command)
+
+	// Resolve ordering before anything starts so cycles fail fast
+	var waitsFor map[string][]string
+	if execOpts.Ordered {
+		var err error
+		waitsFor, err = executionDependencies(profiles, execOpts.Command)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Create channels for streaming communication
 	streamChan := make(chan StreamingOutput, 100)
 	resultsChan := make(chan ExecutionResult, len(profiles))
 	var wg sync.WaitGroup
 
+	// A single profile has nothing to interleave with, so it always streams live
+	e.streamingHandler.Grouped = e.GroupedOutput && len(profiles) > 1
+	e.streamingHandler.BufferLimit = e.OutputBufferSize
+
+	// Colors are settled before the profiles race to print their first line
+	profileNames := make([]string, len(profiles))
+	for i, profile := range profiles {
+		profileNames[i] = profile.Name
+	}
+	e.streamingHandler.colorManager.AssignProfiles(profileNames)
+
 	// Start goroutine to handle streaming output display
 	displayDone := make(chan bool)
 	go e.streamingHandler.DisplayStreamingOutput(streamChan, displayDone)
 
 	// Starts the execution
-	e.executeParallelCommand(profiles, execOpts, streamChan, resultsChan, &wg)
+	e.executeParallelCommand(profiles, execOpts, waitsFor, streamChan, resultsChan, &wg)
 
 	// Wait for all executions to complete
 	wg.Wait()
@@ -164,25 +1014,83 @@ func (e *Executor) parallelExecution(profiles []Profile, execOpts *ExecutionOpti
 		results = append(results, result)
 	}
 
+	// Quiet runs hide the streamed output, so show it for the profiles that failed
+	if e.streamingHandler.Quiet {
+		for _, result := range results {
+			if !result.Success {
+				e.userInteraction.DisplayResult(result)
+				fmt.Fprintln(e.stdout(), strings.Repeat("-", 80))
+			}
+		}
+	}
+
 	return results, nil
 }
 
-// executeParallelCommand executes terraform commands in parallel
-func (e *Executor) executeParallelCommand(profiles []Profile, execOpts *ExecutionOptions, streamChan chan<- StreamingOutput, resultsChan chan<- ExecutionResult, wg *sync.WaitGroup) {
+// executeParallelCommand executes terraform commands in parallel.
+// A profile listed in waitsFor only starts once all the profiles it waits for have finished,
+// and is skipped if any of them failed. Profiles in the same concurrency group run one at a time.
+func (e *Executor) executeParallelCommand(profiles []Profile, execOpts *ExecutionOptions, waitsFor map[string][]string, streamChan chan<- StreamingOutput, resultsChan chan<- ExecutionResult, wg *sync.WaitGroup) {
 	// Create a semaphore to limit concurrency
 	semaphore := make(chan struct{}, e.MaxConcurrency)
 
+	groups := concurrencyGroups(profiles, e.ConcurrencyMode)
+	locks := groupLocks(groups)
+
+	finished := make(map[string]chan struct{}, len(profiles))
+	for _, profile := range profiles {
+		finished[profile.Name] = make(chan struct{})
+	}
+	var failedMutex sync.Mutex
+	failed := make(map[string]bool)
+
 	for _, profile := range profiles {
 		wg.Add(1)
 		go func(prof Profile) {
 			defer wg.Done()
+			defer close(finished[prof.Name])
+			result := ExecutionResult{ProfileName: prof.Name}
+			defer func() {
+				progress := &ProgressiveResult{Result: result, Total: len(profiles), Completed: true}
+				streamChan <- StreamingOutput{ProfileName: prof.Name, Finished: true, Progress: progress, Timestamp: time.Now()}
+			}()
+
+			// Wait for the profiles this one depends on
+			for _, dependency := range waitsFor[prof.Name] {
+				<-finished[dependency]
+
+				failedMutex.Lock()
+				dependencyFailed := failed[dependency]
+				if dependencyFailed {
+					failed[prof.Name] = true
+				}
+				failedMutex.Unlock()
+
+				if dependencyFailed {
+					result = e.errorResultWithStreaming(result,
+						fmt.Errorf("skipped because profile %s failed", dependency), time.Now(), streamChan)
+					resultsChan <- result
+					return
+				}
+			}
+
+			// Wait for the group before taking a slot, so waiting profiles do not block other groups
+			if group, exists := groups[prof.Name]; exists {
+				locks[group].Lock()
+				defer locks[group].Unlock()
+			}
 
 			// Acquire semaphore
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
 			// Execute the command for this profile with streaming
-			result := e.executeForProfileWithStreaming(prof, execOpts, streamChan)
+			result = e.executeForProfileWithStreaming(prof, execOpts, streamChan)
+			if !result.Success {
+				failedMutex.Lock()
+				failed[prof.Name] = true
+				failedMutex.Unlock()
+			}
 			resultsChan <- result
 		}(profile)
 	}
@@ -211,27 +1119,255 @@ func (e *Executor) executeForProfileWithStreaming(profile Profile, execOpts *Exe
 		Timestamp:   time.Now(),
 	}
 
+	// Only names are logged; values may be credentials
+	if len(profile.Env) > 0 {
+		streamChan <- StreamingOutput{
+			ProfileName: profile.Name,
+			Line:        fmt.Sprintf("ENV: setting %s", strings.Join(utils.EnvNames(profile.Env), ", ")),
+			IsError:     false,
+			Timestamp:   time.Now(),
+		}
+	}
+
 	// Initialize terraform if needed
 	workspacePathForInit, _ := e.workspaceManager.GetWorkspacePath(profile.Name)
-	if err := e.initInWorkspaceWithStreaming(profile, workspacePathForInit, streamChan); err != nil {
+	initStatus, err := e.initInWorkspaceWithStreaming(profile, workspacePathForInit, streamChan)
+	result.InitStatus = initStatus
+	if err != nil {
 		return e.errorResultWithStreaming(result, fmt.Errorf("terraform init failed: %w", err), startTime, streamChan)
 	}
 
+	// Init-only runs stop once the workspace is initialized
+	if execOpts.Command == INIT_COMMAND {
+		result.Success = true
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
 	// Build command
-	cmdBuilder := NewCommandBuilder()
-	cmd, err := cmdBuilder.BuildCommandFromProfile(profile, workspacePath, execOpts)
+	buildCommand := func(ctx context.Context) (*exec.Cmd, error) {
+		cmdBuilder := NewCommandBuilder().WithNoColor(!e.Colorize()).WithContext(ctx)
+		if execOpts.PlanFiles != nil {
+			planFile := execOpts.PlanFiles[profile.Name]
+			if exists, _ := utils.CheckFileOrDirExists(planFile); planFile == "" || !exists {
+				return nil, fmt.Errorf("saved plan file not found for profile %s; re-run the plan instead of applying unreviewed changes", profile.Name)
+			}
+			return cmdBuilder.BuildApplyPlanCommand(profile, workspacePath, planFile, execOpts), nil
+		}
+		return cmdBuilder.BuildCommandFromProfile(profile, workspacePath, execOpts)
+	}
+
+	// Execute command with streaming, refreshing expired credentials a limited number of times
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := e.commandContext(profile.Name)
+		cmd, err := buildCommand(ctx)
+		if err != nil {
+			cancel()
+			return e.errorResultWithStreaming(result, fmt.Errorf("command build failed: %w", err), startTime, streamChan)
+		}
+		result = e.executeCommandWithStreaming(ctx, cmd, execOpts, result, startTime, streamChan)
+		cancel()
+
+		var authErr *AuthExpiredError
+		if !errors.As(result.Error, &authErr) || attempt > e.AuthRetries {
+			break
+		}
+
+		streamChan <- StreamingOutput{
+			ProfileName: profile.Name,
+			Line:        fmt.Sprintf("Refreshing %s credentials and retrying (retry %d/%d)...", authErr.Refresher.Name(), attempt, e.AuthRetries),
+			IsError:     false,
+			Timestamp:   time.Now(),
+		}
+		backendConfigPath := NewCommandBuilder().WithWorkingDir(workspacePath).
+			WithBackendConfig(profile.BackendConfig).
+			WithBackendDir(profile.BackendDir).
+			GetBackendConfigPath()
+		if refreshErr := e.refreshAuth(authErr.Refresher, backendConfigPath); refreshErr != nil {
+			result.Error = fmt.Errorf("error refreshing %s credentials: %w", authErr.Refresher.Name(), refreshErr)
+			break
+		}
+
+		// Start the retry from a clean outcome
+		result.Error = nil
+		result.Success = false
+		result.CommandStatus = PhaseNotRun
+		result.ExitCode = 0
+	}
+
+	if execOpts.SavePlan && result.Success {
+		result.PlanFile = filepath.Join(workspacePath, PlanFileName)
+	}
+	if execOpts.JSONPlanDir != "" && execOpts.Command == PREVIEW_COMMAND && result.Success {
+		e.writeJSONPlan(profile, workspacePath, execOpts.JSONPlanDir, &result, streamChan)
+	}
+	return result
+}
+
+// writeJSONPlan renders a profile's saved plan with terraform show -json into dir and tallies its
+// changes. The plan itself stays human-readable, so a failure here only warns.
+func (e *Executor) writeJSONPlan(profile Profile, workspacePath, dir string, result *ExecutionResult, streamChan chan<- StreamingOutput) {
+	var output bytes.Buffer
+	cmd := NewCommandBuilder().BuildShowCommand(profile, workspacePath, PlanFileName, true)
+	cmd.Stdout = &output
+
+	path := filepath.Join(dir, utils.FileSafeName(profile.Name)+".json")
+	err := cmd.Run()
 	if err != nil {
-		return e.errorResultWithStreaming(result, fmt.Errorf("command build failed: %w", err), startTime, streamChan)
+		err = fmt.Errorf("error running terraform show -json: %w", err)
+	}
+	if err == nil {
+		if err = os.MkdirAll(dir, 0700); err == nil {
+			// Plans may contain secrets, so the file is private like the plan itself
+			err = os.WriteFile(path, output.Bytes(), 0600)
+		}
+	}
+
+	line := fmt.Sprintf("JSON plan written to %s", path)
+	if err == nil {
+		if counts, countErr := ParsePlanJSONCounts(output.Bytes()); countErr == nil {
+			result.Changes = &counts
+		}
+	} else {
+		line = fmt.Sprintf("Warning: JSON plan not written: %v", err)
+	}
+	streamChan <- StreamingOutput{
+		ProfileName: profile.Name,
+		Line:        line,
+		IsError:     err != nil,
+		Timestamp:   time.Now(),
+	}
+}
+
+// commandContext returns the context for a single profile command, limited by Timeout when set.
+// The profile can be cancelled by name with CancelProfile until the returned cancel is called.
+func (e *Executor) commandContext(profileName string) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if e.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), e.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	e.runningMutex.Lock()
+	if e.interrupted {
+		cancel()
+	}
+	e.running[profileName] = cancel
+	e.inFlight.Add(1)
+	e.runningMutex.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			e.runningMutex.Lock()
+			delete(e.running, profileName)
+			e.runningMutex.Unlock()
+			cancel()
+			e.inFlight.Done()
+		})
+	}
+}
+
+// errInterrupted is returned for phases that were not started because of an interrupt
+var errInterrupted = errors.New("cancelled by user: interrupted")
+
+// isInterrupted reports whether Interrupt was called, after which no new phase is started
+func (e *Executor) isInterrupted() bool {
+	e.runningMutex.Lock()
+	defer e.runningMutex.Unlock()
+	return e.interrupted
+}
+
+// Interrupt cancels every running profile command, prevents new ones from starting and waits
+// up to timeout for them to shut down, so terraform can release its state locks. When the
+// commands already received the interrupt themselves (delivered), e.g. Ctrl-C reaching the whole
+// process group, they are only cancelled after timeout: terraform treats a second interrupt as a
+// forced abort that leaves the state half-written and locked.
+func (e *Executor) Interrupt(timeout time.Duration, delivered bool) {
+	e.runningMutex.Lock()
+	e.interrupted = true
+	if !delivered {
+		for _, cancel := range e.running {
+			cancel()
+		}
+	}
+	e.runningMutex.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		e.inFlight.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return
+	case <-time.After(timeout):
+	}
+	if !delivered {
+		return
+	}
+
+	// Commands still running after the grace period are stopped for good
+	e.runningMutex.Lock()
+	for _, cancel := range e.running {
+		cancel()
+	}
+	e.runningMutex.Unlock()
+	select {
+	case <-finished:
+	case <-time.After(timeout):
+	}
+}
+
+// CancelProfile interrupts the running command of a single profile, leaving the others running.
+// It reports false when the profile has no running command.
+func (e *Executor) CancelProfile(profileName string) bool {
+	e.runningMutex.Lock()
+	defer e.runningMutex.Unlock()
+
+	cancel, running := e.running[profileName]
+	if running {
+		cancel()
 	}
+	return running
+}
 
-	// Execute command with streaming
-	return e.executeCommandWithStreaming(cmd, result, startTime, streamChan)
+// refreshAuth refreshes credentials one profile at a time. Once a refresh succeeded, the other
+// profiles using the same credentials reuse it instead of each starting a login of their own.
+func (e *Executor) refreshAuth(refresher utils.AuthRefresher, backendConfigPath string) error {
+	e.authMutex.Lock()
+	defer e.authMutex.Unlock()
+
+	key := refresher.Name() + ":" + refresher.Key(backendConfigPath)
+	if e.authRefreshed[key] {
+		return nil
+	}
+	if err := refresher.Refresh(backendConfigPath); err != nil {
+		return err
+	}
+	if e.authRefreshed == nil {
+		e.authRefreshed = make(map[string]bool)
+	}
+	e.authRefreshed[key] = true
+	return nil
 }
 
 // executeCommandWithStreaming executes a command and streams the output
-func (e *Executor) executeCommandWithStreaming(cmd *exec.Cmd, result ExecutionResult, startTime time.Time, streamChan chan<- StreamingOutput) ExecutionResult {
-	var outputBuffer bytes.Buffer
-	var stderrBuffer bytes.Buffer
+func (e *Executor) executeCommandWithStreaming(ctx context.Context, cmd *exec.Cmd, execOpts *ExecutionOptions, result ExecutionResult, startTime time.Time, streamChan chan<- StreamingOutput) ExecutionResult {
+	outputBuffer := utils.NewTailBuffer(e.OutputBufferSize)
+	stderrBuffer := utils.NewTailBuffer(e.OutputBufferSize)
+	// Both streams are also written to one buffer as lines arrive, keeping the stored output chronological
+	combinedBuffer := utils.NewTailBuffer(e.OutputBufferSize)
+
+	logFile, err := e.openLogFile(result.ProfileName, execOpts.Command)
+	if err != nil {
+		return e.errorResultWithStreaming(result, err, startTime, streamChan)
+	}
+	if logFile != nil {
+		defer logFile.Close()
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -253,53 +1389,141 @@ func (e *Executor) executeCommandWithStreaming(cmd *exec.Cmd, result ExecutionRe
 	// stdout
 	go func() {
 		defer wg.Done()
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
+		err := scanLines(stdout, func(line string) {
+			if logFile != nil {
+				logFile.WriteString(line + "\n")
+			}
 			outputBuffer.WriteString(line + "\n")
+			combinedBuffer.WriteString(line + "\n")
+			if execOpts.CaptureOnly {
+				return
+			}
 			streamChan <- StreamingOutput{
 				ProfileName: result.ProfileName,
 				Line:        line,
 				IsError:     false,
 				Timestamp:   time.Now(),
 			}
+		})
+		if output, ok := readErrorOutput(result.ProfileName, err); ok {
+			combinedBuffer.WriteString(output.Line + "\n")
+			streamChan <- output
 		}
 	}()
 
 	// stderr
 	go func() {
 		defer wg.Done()
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
+		err := scanLines(stderr, func(line string) {
 			stderrBuffer.WriteString(line + "\n")
+			combinedBuffer.WriteString(line + "\n")
+			if logFile != nil {
+				logFile.WriteString(line + "\n")
+			}
 			streamChan <- StreamingOutput{
 				ProfileName: result.ProfileName,
 				Line:        line,
 				IsError:     true,
 				Timestamp:   time.Now(),
 			}
+		})
+		if output, ok := readErrorOutput(result.ProfileName, err); ok {
+			combinedBuffer.WriteString(output.Line + "\n")
+			streamChan <- output
+		}
+	}()
+
+	// A timed out command may leave child processes holding the pipes open; close them
+	// after the grace period so this profile's readers exit without affecting the others
+	readersDone := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-readersDone:
+			return
+		}
+		select {
+		case <-time.After(TimeoutGracePeriod):
+			stdout.Close()
+			stderr.Close()
+		case <-readersDone:
 		}
 	}()
 
 	// Wait for both goroutines to finish
 	wg.Wait()
+	close(readersDone)
 
 	// Wait for command to complete
 	err = cmd.Wait()
 	duration := time.Since(startTime)
+	// A command that exited cleanly just before its deadline still succeeded
+	timedOut := err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+	if timedOut {
+		err = fmt.Errorf("timed out after %v: %w", e.Timeout, err)
+	}
+	cancelled := err != nil && errors.Is(ctx.Err(), context.Canceled)
+	if cancelled {
+		err = fmt.Errorf("cancelled by user: %w", err)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	}
+
+	// With --detailed-exitcode terraform reports pending changes as exit code 2
+	if flagEnabled(cmd.Args[1:], "detailed-exitcode") {
+		if result.ExitCode == 2 {
+			err = nil
+		}
+		result.HasChanges = result.ExitCode == 2
+		result.ChangesKnown = err == nil
+	}
+
+	switch {
+	case timedOut:
+		result.CommandStatus = PhaseTimedOut
+	case cancelled:
+		result.CommandStatus = PhaseCancelled
+	case err != nil:
+		result.CommandStatus = PhaseFailed
+	case result.ChangesKnown && result.HasChanges:
+		result.CommandStatus = PhaseChanges
+	case result.ChangesKnown:
+		result.CommandStatus = PhaseNoChanges
+	default:
+		result.CommandStatus = PhaseOK
+	}
 
 	// Combine outputs
-	combinedOutput := outputBuffer.String() + stderrBuffer.String()
+	result.Stdout = outputBuffer.String()
+	combinedOutput := combinedBuffer.String()
+	if combinedBuffer.Truncated() {
+		notice := "... output truncated, showing the most recent lines only"
+		if logFile != nil {
+			notice = fmt.Sprintf("%s (full output in %s)", notice, logFile.Name())
+		}
+		combinedOutput = notice + "\n" + combinedOutput
+	}
 
 	if err != nil {
-		// Check if this is an SSO token error
 		stderrOutput := stderrBuffer.String()
-		if ssoErr := e.handleSSOTokenError(err, stderrOutput, result.ProfileName, streamChan); ssoErr != nil {
-			result.Error = ssoErr
+
+		// A held state lock is reported with its holder rather than as a generic failure
+		if lockErr := e.handleStateLockError(err, stderrOutput, result.ProfileName, streamChan); lockErr != nil {
+			result.CommandStatus = PhaseLocked
+			result.Error = lockErr
 			result.Success = false
 			result.Output = combinedOutput
 			result.Duration = duration
+
+			streamChan <- StreamingOutput{
+				ProfileName: result.ProfileName,
+				Line:        fmt.Sprintf("❌ Execution failed after %v: %s", duration, lockDescription(lockErr.Who, lockErr.ID)),
+				IsError:     true,
+				Timestamp:   time.Now(),
+			}
 			return result
 		}
 
@@ -308,6 +1532,13 @@ func (e *Executor) executeCommandWithStreaming(cmd *exec.Cmd, result ExecutionRe
 		result.Output = combinedOutput
 		result.Duration = duration
 
+		// Expired credentials are refreshed and retried by the caller, unless the command was stopped
+		if !timedOut && !cancelled {
+			if authErr := e.handleSSOTokenError(err, stderrOutput, result.ProfileName, streamChan); authErr != nil {
+				result.Error = authErr
+			}
+		}
+
 		// Send completion message
 		streamChan <- StreamingOutput{
 			ProfileName: result.ProfileName,
@@ -334,26 +1565,81 @@ func (e *Executor) executeCommandWithStreaming(cmd *exec.Cmd, result ExecutionRe
 	return result
 }
 
-func (e *Executor) Init(profile Profile) error {
-	cmdBuilder := NewCommandBuilder().
-		WithBackendConfig(profile.BackendConfig).
-		WithBackendDir(profile.BackendDir)
+// openLogFile opens the per-profile log file in LogDir, returning nil when logging is disabled
+func (e *Executor) openLogFile(profileName, command string) (*os.File, error) {
+	if e.LogDir == "" {
+		return nil, nil
+	}
 
-	backendConfigPath := cmdBuilder.GetBackendConfigPath()
-	exists, err := utils.CheckFileOrDirExists(backendConfigPath)
+	if err := os.MkdirAll(e.LogDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating log directory %s: %w", e.LogDir, err)
+	}
+
+	logPath := filepath.Join(e.LogDir, fmt.Sprintf("%s-%s.log", utils.FileSafeName(profileName), command))
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
-		return fmt.Errorf("error checking backend config file: %w", err)
+		return nil, fmt.Errorf("error opening log file %s: %w", logPath, err)
 	}
-	if !exists {
-		return fmt.Errorf("backend config file not found: %s", backendConfigPath)
+
+	fmt.Fprintf(logFile, "# tapper %s for profile %s at %s%s\n", command, profileName, time.Now().Format(time.RFC3339), e.annotationSuffix())
+	return logFile, nil
+}
+
+// annotationSuffix formats the run reason and ticket for log and display headers
+func (e *Executor) annotationSuffix() string {
+	var parts []string
+	if e.Ticket != "" {
+		parts = append(parts, fmt.Sprintf("ticket: %s", e.Ticket))
+	}
+	if e.Reason != "" {
+		parts = append(parts, fmt.Sprintf("reason: %s", e.Reason))
 	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+}
+
+// InstallDependencies installs the module's providers and modules once in the module directory,
+// without configuring a backend. Workspaces share them through their .terraform symlinks, so each
+// workspace's own init only configures its backend instead of downloading everything again.
+func (e *Executor) InstallDependencies(profile Profile) error {
+	if _, installArgs := splitInitArgs(e.InitArgs); !e.Reinit && len(installArgs) == 0 && !installRequired(profile.ModuleDir) {
+		fmt.Fprintln(e.stdout(), "Providers and modules already installed, skipping install")
+		return nil
+	}
+
+	startTime := time.Now()
+	if err := e.runInstall(profile); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout(), "Providers and modules installed in %s\n", time.Since(startTime).Round(time.Millisecond))
+	return recordInstall(profile.ModuleDir)
+}
+
+// runInstall runs terraform init -backend=false in the module directory, refreshing expired
+// cloud credentials (e.g. for modules fetched from S3) if needed
+func (e *Executor) runInstall(profile Profile) error {
+	cmdBuilder := NewCommandBuilder().
+		WithWorkingDir(profile.ModuleDir).
+		WithNoColor(!e.Colorize()).
+		WithEnv(e.installEnv(profile)).
+		WithInitArgs(e.InitArgs).
+		WithBackendConfig(profile.BackendConfig).
+		WithBackendDir(profile.BackendDir)
+	backendConfigPath := cmdBuilder.GetBackendConfigPath()
 
-	cmd := cmdBuilder.BuildInitCommand()
+	// The install is tracked like a profile command, so an interrupt stops it and waits for it
+	ctx, cancel := e.commandContext("install:" + profile.ModuleDir)
+	defer cancel()
+	cmdBuilder = cmdBuilder.WithContext(ctx)
+
+	cmd := cmdBuilder.BuildInstallCommand()
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return fmt.Errorf("error creating stderr pipe: %w", err)
 	}
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = e.stdout()
 
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("error starting terraform init: %w", err)
@@ -365,18 +1651,17 @@ func (e *Executor) Init(profile Profile) error {
 	// Wait for command to finish
 	err = cmd.Wait()
 
-	// If there was an error, check for SSO token error
-	// Currently checks specifically for AWS-related errors.
-	if err != nil && utils.IsAWSSSOTokenExpired(stderrOutput) {
-		fmt.Println("AWS SSO session has expired. Attempting to login...")
+	// If there was an error, check for expired cloud credentials
+	if refresher := utils.MatchAuthRefresher(stderrOutput); err != nil && ctx.Err() == nil && refresher != nil {
+		fmt.Fprintf(e.stdout(), "%s session has expired. Attempting to login...\n", refresher.Name())
 
-		if refreshErr := utils.RefreshAWSSSOFromBackendConfig(backendConfigPath); refreshErr != nil {
-			return fmt.Errorf("error refreshing AWS SSO token: %w", refreshErr)
+		if refreshErr := refresher.Refresh(backendConfigPath); refreshErr != nil {
+			return fmt.Errorf("error refreshing %s credentials: %w", refresher.Name(), refreshErr)
 		}
 
-		// Run init again
-		retryCmd := cmdBuilder.BuildInitCommand()
-		retryCmd.Stdout = os.Stdout
+		// Run the install again
+		retryCmd := cmdBuilder.BuildInstallCommand()
+		retryCmd.Stdout = e.stdout()
 		retryCmd.Stderr = os.Stderr
 
 		return retryCmd.Run()
@@ -410,6 +1695,11 @@ func (e *Executor) errorResultWithStreaming(result ExecutionResult, err error, s
 	result.Success = false
 	result.Duration = time.Since(startTime)
 
+	// Errors after a completed init are attributed to the command phase
+	if result.CommandStatus == PhaseNotRun && (result.InitStatus == PhaseOK || result.InitStatus == PhaseSkipped) {
+		result.CommandStatus = PhaseFailed
+	}
+
 	streamChan <- StreamingOutput{
 		ProfileName: result.ProfileName,
 		Line:        fmt.Sprintf("❌ Error: %v", err),
@@ -422,18 +1712,86 @@ func (e *Executor) errorResultWithStreaming(result ExecutionResult, err error, s
 
 // WorkspaceCleanup cleans up the created workspaces by the last execution
 func (e *Executor) WorkspaceCleanup(plan *ExecutionPlan) error {
+	// Cleanup may run from both an interrupt handler and the normal exit path
+	e.cleanupMutex.Lock()
+	defer e.cleanupMutex.Unlock()
+	if e.cleanedUp {
+		return nil
+	}
+
+	if e.KeepWorkspaces {
+		e.printKeptWorkspaces()
+		e.cleanedUp = true
+		return nil
+	}
+
+	// Saved plans may contain sensitive values, remove them explicitly
+	if plan != nil {
+		for _, result := range plan.Results {
+			if result.PlanFile == "" {
+				continue
+			}
+			if err := os.Remove(result.PlanFile); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing plan file %s: %w", result.PlanFile, err)
+			}
+		}
+	}
+
 	if e.workspaceManager != nil {
-		return e.workspaceManager.Cleanup()
+		if err := e.workspaceManager.Cleanup(); err != nil {
+			return err
+		}
 	}
+	e.cleanedUp = true
 	return nil
 }
 
-// initInWorkspaceWithStreaming runs terraform init in a workspace with streaming output
-func (e *Executor) initInWorkspaceWithStreaming(profile Profile, workspacePath string, streamChan chan<- StreamingOutput) error {
-	cmd := NewCommandBuilder().WithWorkingDir(workspacePath).
+// printKeptWorkspaces lists the workspaces left in place by KeepWorkspaces
+func (e *Executor) printKeptWorkspaces() {
+	if e.workspaceManager == nil {
+		return
+	}
+	workspaces := e.workspaceManager.Mapping().Workspaces
+	if len(workspaces) == 0 {
+		return
+	}
+	names := make([]string, 0, len(workspaces))
+	for name := range workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(e.stdout(), "Keeping workspaces (delete them when you are done):")
+	for _, name := range names {
+		fmt.Fprintf(e.stdout(), "  %s: %s\n", name, workspaces[name])
+	}
+}
+
+// initInWorkspaceWithStreaming runs terraform init in a workspace with streaming output.
+// Expired credentials are refreshed and init retried, like for the profile's command.
+func (e *Executor) initInWorkspaceWithStreaming(profile Profile, workspacePath string, streamChan chan<- StreamingOutput) (PhaseStatus, error) {
+	if e.isInterrupted() {
+		return PhaseCancelled, errInterrupted
+	}
+	if backendArgs, _ := splitInitArgs(e.InitArgs); !e.Reinit && len(backendArgs) == 0 && !initRequired(workspacePath, profile) {
+		streamChan <- StreamingOutput{
+			ProfileName: profile.Name,
+			Line:        "INIT: ✅ Already initialized, skipping init",
+			IsError:     false,
+			Timestamp:   time.Now(),
+		}
+		return PhaseSkipped, nil
+	}
+
+	cmdBuilder := NewCommandBuilder().WithWorkingDir(workspacePath).
+		WithNoColor(!e.Colorize()).
+		WithEnv(e.initEnv(profile)).
+		WithInitArgs(e.InitArgs).
 		WithBackendConfig(profile.BackendConfig).
-		WithBackendDir(profile.BackendDir).
-		BuildInitCommand()
+		WithBackendDir(profile.BackendDir)
+	if err := cmdBuilder.ResolveBackend(); err != nil {
+		return PhaseFailed, err
+	}
 
 	streamChan <- StreamingOutput{
 		ProfileName: profile.Name,
@@ -441,18 +1799,106 @@ func (e *Executor) initInWorkspaceWithStreaming(profile Profile, workspacePath s
 		IsError:     false,
 		Timestamp:   time.Now(),
 	}
+	startTime := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		// Init is limited by --timeout and can be cancelled like the profile's command
+		ctx, cancel := e.commandContext(profile.Name)
+		stderrOutput, err := e.streamInit(cmdBuilder.WithContext(ctx).BuildInitCommand(), profile.Name, streamChan)
+		ctxErr := ctx.Err()
+		cancel()
+		if err == nil {
+			break
+		}
+
+		status := PhaseFailed
+		switch {
+		case errors.Is(ctxErr, context.DeadlineExceeded):
+			status = PhaseTimedOut
+			err = fmt.Errorf("timed out after %v: %w", e.Timeout, err)
+		case errors.Is(ctxErr, context.Canceled):
+			status = PhaseCancelled
+			err = fmt.Errorf("cancelled by user: %w", err)
+		}
+
+		refresher := utils.MatchAuthRefresher(stderrOutput)
+		if status == PhaseFailed && refresher != nil && attempt <= e.AuthRetries {
+			streamChan <- StreamingOutput{
+				ProfileName: profile.Name,
+				Line:        fmt.Sprintf("INIT: Refreshing %s credentials and retrying (retry %d/%d)...", refresher.Name(), attempt, e.AuthRetries),
+				IsError:     false,
+				Timestamp:   time.Now(),
+			}
+			refreshErr := e.refreshAuth(refresher, cmdBuilder.GetBackendConfigPath())
+			if refreshErr == nil {
+				continue
+			}
+			err = fmt.Errorf("error refreshing %s credentials: %w", refresher.Name(), refreshErr)
+		}
+
+		streamChan <- StreamingOutput{
+			ProfileName: profile.Name,
+			Line:        fmt.Sprintf("INIT: ❌ Failed: %v", err),
+			IsError:     true,
+			Timestamp:   time.Now(),
+		}
+		return status, err
+	}
+
+	streamChan <- StreamingOutput{
+		ProfileName: profile.Name,
+		Line:        fmt.Sprintf("INIT: ✅ Terraform initialized successfully in %s", time.Since(startTime).Round(time.Millisecond)),
+		IsError:     false,
+		Timestamp:   time.Now(),
+	}
+
+	if err := recordInit(workspacePath, profile); err != nil {
+		return PhaseOK, err
+	}
+	return PhaseOK, nil
+}
+
+// initEnv returns the profile's environment for init, pointing terraform at the shared plugin
+// cache unless the profile sets its own
+func (e *Executor) initEnv(profile Profile) map[string]string {
+	cacheDir := ""
+	if e.workspaceManager != nil {
+		cacheDir = e.workspaceManager.PluginCacheDir
+	}
+	if _, overridden := profile.Env["TF_PLUGIN_CACHE_DIR"]; cacheDir == "" || overridden {
+		return profile.Env
+	}
+
+	env := map[string]string{"TF_PLUGIN_CACHE_DIR": cacheDir}
+	for name, value := range profile.Env {
+		env[name] = value
+	}
+	return env
+}
+
+// installEnv returns the profile's environment for the module install. Providers installed from
+// a cache link into it, and the module's .terraform outlives the run, so a per-run cache removed
+// on cleanup is never used here.
+func (e *Executor) installEnv(profile Profile) map[string]string {
+	if e.workspaceManager != nil && !e.workspaceManager.PersistentCache {
+		return profile.Env
+	}
+	return e.initEnv(profile)
+}
 
+// streamInit runs an init command, streaming its output, and returns what it wrote to stderr
+func (e *Executor) streamInit(cmd *exec.Cmd, profileName string, streamChan chan<- StreamingOutput) (string, error) {
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return err
+		return "", err
 	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if err := cmd.Start(); err != nil {
-		return err
+		return "", err
 	}
 
 	var wg sync.WaitGroup
@@ -461,66 +1907,87 @@ func (e *Executor) initInWorkspaceWithStreaming(profile Profile, workspacePath s
 	// stdout
 	go func() {
 		defer wg.Done()
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
+		err := scanLines(stdout, func(line string) {
 			streamChan <- StreamingOutput{
-				ProfileName: profile.Name,
+				ProfileName: profileName,
 				Line:        fmt.Sprintf("INIT: %s", line),
 				IsError:     false,
 				Timestamp:   time.Now(),
 			}
+		})
+		if output, ok := readErrorOutput(profileName, err); ok {
+			streamChan <- output
 		}
 	}()
 
-	// stderr
+	// stderr, kept to detect expired credentials
+	var stderrOutput strings.Builder
 	go func() {
 		defer wg.Done()
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
+		err := scanLines(stderr, func(line string) {
+			stderrOutput.WriteString(line + "\n")
 			streamChan <- StreamingOutput{
-				ProfileName: profile.Name,
+				ProfileName: profileName,
 				Line:        fmt.Sprintf("INIT ERROR: %s", line),
 				IsError:     true,
 				Timestamp:   time.Now(),
 			}
+		})
+		if output, ok := readErrorOutput(profileName, err); ok {
+			streamChan <- output
 		}
 	}()
 
 	wg.Wait()
+	return stderrOutput.String(), cmd.Wait()
+}
 
-	if err := cmd.Wait(); err != nil {
-		streamChan <- StreamingOutput{
-			ProfileName: profile.Name,
-			Line:        fmt.Sprintf("INIT: ❌ Failed: %v", err),
-			IsError:     true,
-			Timestamp:   time.Now(),
-		}
-		return err
+// AuthExpiredError reports a command that failed because its cloud credentials expired
+type AuthExpiredError struct {
+	Refresher utils.AuthRefresher
+	Err       error
+}
+
+func (e *AuthExpiredError) Error() string {
+	return fmt.Sprintf("%s token error: %v", e.Refresher.Name(), e.Err)
+}
+
+func (e *AuthExpiredError) Unwrap() error {
+	return e.Err
+}
+
+// handleStateLockError highlights a held state lock with its holder and how to release it
+func (e *Executor) handleStateLockError(err error, stderrOutput string, profileName string, streamChan chan<- StreamingOutput) *StateLockedError {
+	if !IsStateLockError(stderrOutput) {
+		return nil
 	}
 
+	lockErr := newStateLockedError(stderrOutput, err)
 	streamChan <- StreamingOutput{
-		ProfileName: profile.Name,
-		Line:        "INIT: ✅ Terraform initialized successfully",
-		IsError:     false,
+		ProfileName: profileName,
+		Line:        "🔒 Another run holds the lock: " + lockDescription(lockErr.Who, lockErr.ID),
+		IsError:     true,
 		Timestamp:   time.Now(),
 	}
-
-	return nil
+	streamChan <- StreamingOutput{
+		ProfileName: profileName,
+		Line:        "⚠️  " + lockErr.Guidance(),
+		IsError:     true,
+		Timestamp:   time.Now(),
+	}
+	return lockErr
 }
 
-// handleSSOTokenError handles SSO token errors
+// handleSSOTokenError reports expired credentials that a known refresher can renew
 func (e *Executor) handleSSOTokenError(err error, stderrOutput string, profileName string, streamChan chan<- StreamingOutput) error {
-	// Check if the error is related to SSO token issues
-	if strings.Contains(stderrOutput, "SSO") || strings.Contains(stderrOutput, "token") {
+	if refresher := utils.MatchAuthRefresher(stderrOutput); refresher != nil {
 		streamChan <- StreamingOutput{
 			ProfileName: profileName,
-			Line:        "⚠️  SSO token error detected. Please refresh your SSO token and try again.",
+			Line:        fmt.Sprintf("⚠️  %s credentials have expired.", refresher.Name()),
 			IsError:     true,
 			Timestamp:   time.Now(),
 		}
-		return fmt.Errorf("SSO token error: %w", err)
+		return &AuthExpiredError{Refresher: refresher, Err: err}
 	}
 	return nil
 }
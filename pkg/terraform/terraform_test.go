@@ -0,0 +1,229 @@
+package terraform
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultsError(t *testing.T) {
+	if err := resultsError([]ExecutionResult{{ProfileName: "dev", Success: true}}); err != nil {
+		t.Errorf("Expected no error when all profiles succeed, got: %v", err)
+	}
+
+	results := []ExecutionResult{
+		{ProfileName: "prod", Error: errors.New("exit status 1")},
+		{ProfileName: "dev", Success: true},
+		{ProfileName: "staging"},
+	}
+
+	err := resultsError(results)
+	if err == nil {
+		t.Fatal("Expected an error when profiles failed")
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 joined errors, got: %q", err.Error())
+	}
+	if lines[0] != "profile prod: exit status 1" {
+		t.Errorf("Unexpected first error: %q", lines[0])
+	}
+	if lines[1] != "profile staging: execution failed" {
+		t.Errorf("Unexpected second error: %q", lines[1])
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	executor := &Executor{running: make(map[string]context.CancelFunc)}
+
+	ctx, cancel := executor.commandContext("dev")
+	finished := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(finished)
+		cancel()
+		cancel() // Cancelling twice must not unbalance the in-flight count
+	}()
+
+	executor.Interrupt(time.Second, false)
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Expected Interrupt to wait for the running profile")
+	}
+
+	// Profiles started after an interrupt are cancelled immediately
+	lateCtx, lateCancel := executor.commandContext("prod")
+	defer lateCancel()
+	if lateCtx.Err() == nil {
+		t.Error("Expected new commands to start cancelled after an interrupt")
+	}
+
+	// No new phase starts after an interrupt
+	if err := executor.prepareWorkspaces([]Profile{{Name: "dev"}}); !errors.Is(err, errInterrupted) {
+		t.Errorf("Expected workspace preparation to be skipped after an interrupt, got: %v", err)
+	}
+	if status, err := executor.initInWorkspaceWithStreaming(Profile{Name: "dev"}, t.TempDir(), nil); status != PhaseCancelled || !errors.Is(err, errInterrupted) {
+		t.Errorf("Expected init to be skipped after an interrupt, got: %v, %v", status, err)
+	}
+
+	// The interrupt handler and the normal exit path may both clean up
+	if err := executor.WorkspaceCleanup(nil); err != nil {
+		t.Errorf("Expected no error cleaning up, got: %v", err)
+	}
+	if err := executor.WorkspaceCleanup(nil); err != nil {
+		t.Errorf("Expected a second cleanup to be a no-op, got: %v", err)
+	}
+}
+
+func TestInterruptDelivered(t *testing.T) {
+	executor := &Executor{running: make(map[string]context.CancelFunc)}
+
+	// A command that exits on the interrupt it received itself is never cancelled
+	ctx, cancel := executor.commandContext("dev")
+	cancelledEarly := make(chan error, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancelledEarly <- ctx.Err()
+		cancel()
+	}()
+	executor.Interrupt(time.Second, true)
+	if err := <-cancelledEarly; err != nil {
+		t.Fatalf("Expected no second interrupt for a delivered signal, got: %v", err)
+	}
+
+	// One still running after the grace period is cancelled
+	executor = &Executor{running: make(map[string]context.CancelFunc)}
+	ctx, cancel = executor.commandContext("prod")
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		cancel()
+	}()
+	executor.Interrupt(10*time.Millisecond, true)
+	if ctx.Err() == nil {
+		t.Error("Expected the command to be cancelled after the grace period")
+	}
+}
+
+func TestApplyNoChangesPolicySkip(t *testing.T) {
+	executor := &Executor{NoChangesPolicy: NoChangesSkip}
+	plan := &ExecutionPlan{
+		Results: []ExecutionResult{
+			{ProfileName: "dev", Success: true, ChangesKnown: true},
+			{ProfileName: "prod", Success: true, ChangesKnown: true, HasChanges: true},
+		},
+	}
+
+	if err := executor.applyNoChangesPolicy(plan); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(plan.SkippedProfiles) != 1 || plan.SkippedProfiles[0] != "dev" {
+		t.Errorf("Expected only the unchanged profile to be skipped, got: %v", plan.SkippedProfiles)
+	}
+	if len(plan.ReviewedProfiles) != 1 || plan.ReviewedProfiles[0] != "dev" {
+		t.Errorf("Expected the skipped profile to bypass review, got: %v", plan.ReviewedProfiles)
+	}
+}
+
+func TestExecuteCommandWithStreamingChronologicalOutput(t *testing.T) {
+	executor := &Executor{}
+	cmd := exec.Command("sh", "-c", "echo one; sleep 0.1; echo two >&2; sleep 0.1; echo three")
+	streamChan := make(chan StreamingOutput, 16)
+
+	result := executor.executeCommandWithStreaming(context.Background(), cmd, &ExecutionOptions{Command: "apply"},
+		ExecutionResult{ProfileName: "dev"}, time.Now(), streamChan)
+
+	if result.Output != "one\ntwo\nthree\n" {
+		t.Errorf("Expected stdout and stderr in the order they were written, got: %q", result.Output)
+	}
+	if result.Stdout != "one\nthree\n" {
+		t.Errorf("Expected stdout alone in Stdout, got: %q", result.Stdout)
+	}
+}
+
+func TestExecuteCommandWithStreamingFinishedBeforeDeadline(t *testing.T) {
+	executor := &Executor{}
+	cmd := exec.Command("sh", "-c", "echo done")
+	streamChan := make(chan StreamingOutput, 16)
+
+	// The deadline passing after terraform exited cleanly does not turn its success into a timeout
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	result := executor.executeCommandWithStreaming(ctx, cmd, &ExecutionOptions{Command: "apply"},
+		ExecutionResult{ProfileName: "dev"}, time.Now(), streamChan)
+
+	if !result.Success || result.CommandStatus != PhaseOK {
+		t.Errorf("Expected a successful command, got status %s: %v", result.CommandStatus, result.Error)
+	}
+}
+
+func TestExecuteCommandWithStreamingFinishedBeforeCancel(t *testing.T) {
+	executor := &Executor{}
+	cmd := exec.Command("sh", "-c", "echo done")
+	streamChan := make(chan StreamingOutput, 16)
+
+	// Cancelling a profile that already exited cleanly leaves its success intact
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := executor.executeCommandWithStreaming(ctx, cmd, &ExecutionOptions{Command: "apply"},
+		ExecutionResult{ProfileName: "dev"}, time.Now(), streamChan)
+
+	if !result.Success || result.CommandStatus != PhaseOK {
+		t.Errorf("Expected a successful command, got status %s: %v", result.CommandStatus, result.Error)
+	}
+}
+
+// countingRefresher counts its refreshes and fails the first failures of them
+type countingRefresher struct {
+	refreshes int
+	failures  int
+}
+
+func (r *countingRefresher) Name() string                        { return "test" }
+func (r *countingRefresher) Matches(stderr string) bool          { return false }
+func (r *countingRefresher) Key(backendConfigPath string) string { return backendConfigPath }
+func (r *countingRefresher) Refresh(backendConfigPath string) error {
+	r.refreshes++
+	if r.refreshes <= r.failures {
+		return errors.New("login failed")
+	}
+	return nil
+}
+
+func TestRefreshAuthOncePerCredentials(t *testing.T) {
+	executor := &Executor{}
+	refresher := &countingRefresher{failures: 1}
+
+	// A failed refresh is retried by the next profile
+	if err := executor.refreshAuth(refresher, "backend/dev.tfbackend"); err == nil {
+		t.Fatal("Expected the first refresh to fail")
+	}
+	for i := 0; i < 3; i++ {
+		if err := executor.refreshAuth(refresher, "backend/dev.tfbackend"); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if refresher.refreshes != 2 {
+		t.Errorf("Expected profiles sharing credentials to reuse the successful refresh, got %d refreshes", refresher.refreshes)
+	}
+
+	executor.refreshAuth(refresher, "backend/prod.tfbackend")
+	if refresher.refreshes != 3 {
+		t.Errorf("Expected other credentials to be refreshed separately, got %d refreshes", refresher.refreshes)
+	}
+}
+
+func TestPassthroughRequiresAutoApproveForChanges(t *testing.T) {
+	executor := &Executor{userInteraction: NewInteractionHandler()}
+	for _, command := range []string{"apply", "destroy", REFRESH_COMMAND} {
+		_, err := executor.Passthrough(command, Profile{Name: "dev"})
+		if err == nil || !strings.Contains(err.Error(), "requires --auto-approve") {
+			t.Errorf("Expected %s passthrough without --auto-approve to be rejected, got: %v", command, err)
+		}
+	}
+}
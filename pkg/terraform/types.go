@@ -6,23 +6,60 @@ import (
 
 // ExecutionPlan represents a plan for execution across multiple profiles
 type ExecutionPlan struct {
-	Command          string
-	Profiles         []Profile
-	Results          []ExecutionResult
-	ApprovedProfiles []string
+	Command          string            `json:"command"`
+	Profiles         []Profile         `json:"profiles"`
+	Results          []ExecutionResult `json:"results"`
+	ApprovedProfiles []string          `json:"approvedprofiles"`
+	ReviewedProfiles []string          `json:"reviewedprofiles"`
+	SkippedProfiles  []string          `json:"skippedprofiles,omitempty"` // Left out because their plan had no changes
+	Reason           string            `json:"reason,omitempty"`
+	Ticket           string            `json:"ticket,omitempty"`
 }
 
 // ExecutionResult represents the result of executing a terraform command for a profile
 type ExecutionResult struct {
-	ProfileName string
-	Success     bool
-	Output      string
-	Error       error
-	Duration    time.Duration
-	WorkingDir  string
+	ProfileName   string        `json:"profilename"`
+	Success       bool          `json:"success"`
+	Output        string        `json:"output"`
+	Stdout        string        `json:"-"`
+	Error         error         `json:"-"`
+	ErrorMessage  string        `json:"error,omitempty"`
+	Duration      time.Duration `json:"duration"`
+	WorkingDir    string        `json:"workingdir"`
+	PlanFile      string        `json:"planfile,omitempty"`
+	ExitCode      int           `json:"exitcode"`
+	HasChanges    bool          `json:"haschanges"`
+	ChangesKnown  bool          `json:"changesknown"` // Whether HasChanges was reported by --detailed-exitcode
+	InitStatus    PhaseStatus   `json:"initstatus"`
+	CommandStatus PhaseStatus   `json:"commandstatus"`
+	Changes       *PlanCounts   `json:"changes,omitempty"` // Tallied from the JSON plan written with --json-plan
 }
 
-// ProgressiveResult wraps ExecutionResult with metadata for progressive display
+// ProcessExitCode returns terraform's exit code, or 1 for failures that never reached terraform
+func (r ExecutionResult) ProcessExitCode() int {
+	if r.ExitCode != 0 || r.Success {
+		return r.ExitCode
+	}
+	return 1
+}
+
+// PhaseStatus describes the outcome of a single execution phase (init, plan, apply...)
+type PhaseStatus string
+
+const (
+	PhaseNotRun    PhaseStatus = ""
+	PhaseOK        PhaseStatus = "ok"
+	PhaseSkipped   PhaseStatus = "skipped"
+	PhaseChanges   PhaseStatus = "changes"
+	PhaseNoChanges PhaseStatus = "no changes"
+	PhaseFailed    PhaseStatus = "failed"
+	PhaseLocked    PhaseStatus = "locked"
+	PhaseTimedOut  PhaseStatus = "timed out"
+	PhaseCancelled PhaseStatus = "cancelled"
+)
+
+// ProgressiveResult wraps ExecutionResult with metadata for progressive display: Index is the
+// number of profiles completed so far, out of Total, and is set by the display as results arrive
 type ProgressiveResult struct {
 	Result    ExecutionResult
 	Index     int
@@ -0,0 +1,83 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UsageStateFile stores when each profile was last used, keyed by profile name
+const UsageStateFile = ".tapper/state.json"
+
+// NeverUsed is shown for profiles that have not been run yet
+const NeverUsed = "never"
+
+// usageState represents the persisted profile usage
+type usageState struct {
+	LastUsed map[string]string `json:"lastused"`
+}
+
+// loadUsage reads the usage state, returning an empty state when none exists. The state only
+// records last use, so a corrupt file is reported and ignored rather than failing the command.
+func loadUsage(path string) (*usageState, error) {
+	state := &usageState{LastUsed: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading usage state %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring unreadable usage state %s: %v\n", path, err)
+		return &usageState{LastUsed: map[string]string{}}, nil
+	}
+	if state.LastUsed == nil {
+		state.LastUsed = map[string]string{}
+	}
+	return state, nil
+}
+
+// applyUsage sets LastUsed on the profiles from the usage state
+func applyUsage(state *usageState, profiles []Profile) {
+	for i := range profiles {
+		if lastUsed, exists := state.LastUsed[profiles[i].Name]; exists {
+			profiles[i].LastUsed = lastUsed
+		} else {
+			profiles[i].LastUsed = NeverUsed
+		}
+	}
+}
+
+// RecordProfileUsage stores the current time as the last use of the given profiles
+func RecordProfileUsage(profileNames []string) error {
+	if len(profileNames) == 0 {
+		return nil
+	}
+
+	state, err := loadUsage(UsageStateFile)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	for _, name := range profileNames {
+		state.LastUsed[name] = now
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding usage state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(UsageStateFile), 0755); err != nil {
+		return fmt.Errorf("error creating state directory: %w", err)
+	}
+	if err := os.WriteFile(UsageStateFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing usage state %s: %w", UsageStateFile, err)
+	}
+	return nil
+}
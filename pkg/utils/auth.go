@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AuthRefresher detects expired cloud credentials in terraform output and refreshes them
+type AuthRefresher interface {
+	// Name identifies the credential source in messages, e.g. "AWS SSO"
+	Name() string
+	// Matches reports whether stderr indicates these credentials have expired
+	Matches(stderr string) bool
+	// Refresh renews the credentials used by the given backend config file
+	Refresh(backendConfigPath string) error
+	// Key identifies the credentials Refresh renews for the backend config file, so profiles
+	// sharing them are refreshed once
+	Key(backendConfigPath string) string
+}
+
+var (
+	authRefreshersMutex sync.RWMutex
+	authRefreshers      []AuthRefresher
+)
+
+func init() {
+	RegisterAuthRefresher(AWSSSORefresher{})
+}
+
+// RegisterAuthRefresher adds a refresher consulted when terraform fails with expired credentials
+func RegisterAuthRefresher(refresher AuthRefresher) {
+	authRefreshersMutex.Lock()
+	defer authRefreshersMutex.Unlock()
+	authRefreshers = append(authRefreshers, refresher)
+}
+
+// MatchAuthRefresher returns the first registered refresher matching stderr, or nil
+func MatchAuthRefresher(stderr string) AuthRefresher {
+	authRefreshersMutex.RLock()
+	defer authRefreshersMutex.RUnlock()
+
+	for _, refresher := range authRefreshers {
+		if refresher.Matches(stderr) {
+			return refresher
+		}
+	}
+	return nil
+}
+
+// AWSSSORefresher refreshes AWS SSO sessions using the profile from the backend config
+type AWSSSORefresher struct{}
+
+// Name identifies AWS SSO in messages
+func (AWSSSORefresher) Name() string {
+	return "AWS SSO"
+}
+
+// Matches reports whether stderr contains the AWS SSO expired token error
+func (AWSSSORefresher) Matches(stderr string) bool {
+	return IsAWSSSOTokenExpired(stderr)
+}
+
+// Refresh runs aws sso login for the profile named in the backend config
+func (AWSSSORefresher) Refresh(backendConfigPath string) error {
+	if backendConfigPath == "" {
+		return fmt.Errorf("no backend config file to read the AWS profile from")
+	}
+	return RefreshAWSSSOFromBackendConfig(backendConfigPath)
+}
+
+// Key identifies the AWS profile named in the backend config, or the file when it names none
+func (AWSSSORefresher) Key(backendConfigPath string) string {
+	profileName, err := awsProfileFromBackendConfig(backendConfigPath)
+	if err != nil {
+		return backendConfigPath
+	}
+	return profileName
+}
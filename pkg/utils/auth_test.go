@@ -0,0 +1,14 @@
+package utils
+
+import "testing"
+
+func TestMatchAuthRefresher(t *testing.T) {
+	refresher := MatchAuthRefresher("Error: " + SSOTokenExpiredError)
+	if refresher == nil || refresher.Name() != "AWS SSO" {
+		t.Fatalf("Expected the AWS SSO refresher to match, got: %v", refresher)
+	}
+
+	if refresher := MatchAuthRefresher("Error: Invalid reference"); refresher != nil {
+		t.Errorf("Expected no refresher for unrelated errors, got: %s", refresher.Name())
+	}
+}
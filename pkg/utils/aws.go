@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -39,13 +40,27 @@ func ExtractProfileFromBackendConfig(content string) (string, error) {
 	return "", fmt.Errorf("profile parameter not found in backend config")
 }
 
+// ExtractProfileFromJSONBackendConfig extracts the profile value from a JSON backend config
+func ExtractProfileFromJSONBackendConfig(content string) (string, error) {
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		return "", fmt.Errorf("error parsing JSON backend config: %w", err)
+	}
+
+	if profile, ok := config["profile"].(string); ok && profile != "" {
+		return profile, nil
+	}
+	return "", fmt.Errorf("profile parameter not found in backend config")
+}
+
 // RefreshAWSSSO runs aws sso login with the specified profile
 func RefreshAWSSSO(profileName string) error {
-	fmt.Printf("Running AWS SSO login for profile '%s'...\n", profileName)
+	// Login prompts go to stderr so they never mix into a command's result on stdout
+	fmt.Fprintf(os.Stderr, "Running AWS SSO login for profile '%s'...\n", profileName)
 
 	// Run aws sso login with the profile
 	cmd := exec.Command("aws", "sso", "login", "--profile", profileName)
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = os.Stderr
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
@@ -57,17 +72,29 @@ func RefreshAWSSSO(profileName string) error {
 
 // RefreshAWSSSOFromBackendConfig reads the backend config file and refreshes SSO for the profile found
 func RefreshAWSSSOFromBackendConfig(backendConfigPath string) error {
-	data, err := os.ReadFile(backendConfigPath)
+	profileName, err := awsProfileFromBackendConfig(backendConfigPath)
 	if err != nil {
-		return fmt.Errorf("error reading backend config file: %w", err)
+		return err
 	}
+	return RefreshAWSSSO(profileName)
+}
 
-	profileName, err := ExtractProfileFromBackendConfig(string(data))
+// awsProfileFromBackendConfig reads the AWS profile named in a .tfbackend or JSON backend config file
+func awsProfileFromBackendConfig(backendConfigPath string) (string, error) {
+	data, err := os.ReadFile(backendConfigPath)
 	if err != nil {
-		return fmt.Errorf("error extracting profile from backend config: %w", err)
+		return "", fmt.Errorf("error reading backend config file: %w", err)
 	}
 
-	return RefreshAWSSSO(profileName)
+	extract := ExtractProfileFromBackendConfig
+	if strings.HasSuffix(backendConfigPath, ".json") {
+		extract = ExtractProfileFromJSONBackendConfig
+	}
+	profileName, err := extract(string(data))
+	if err != nil {
+		return "", fmt.Errorf("error extracting profile from backend config: %w", err)
+	}
+	return profileName, nil
 }
 
 // IsAWSSSOTokenExpired checks if the given error output indicates an expired SSO token
@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// backendBlockPattern matches the header of a backend "type" or cloud block
+var backendBlockPattern = regexp.MustCompile(`^(backend\s+"[^"]*"|cloud)$`)
+
+// HasBackendBlock reports whether any .tf file directly in dir configures a backend or HCP Terraform
+func HasBackendBlock(dir string) (bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return false, fmt.Errorf("error listing terraform files: %w", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return false, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		if configuresBackend(string(data)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// configuresBackend reports whether HCL content has a backend or cloud block directly inside a
+// terraform block, wherever the braces are, e.g. terraform { backend "s3" {} } on a single line
+func configuresBackend(content string) bool {
+	var blocks []string
+	for _, line := range strings.Split(content, "\n") {
+		line = stripHCLComment(line)
+		header := ""
+		inString := false
+		for i := 0; i < len(line); i++ {
+			switch c := line[i]; {
+			case c == '\\' && inString:
+				header += line[i:min(i+2, len(line))]
+				i++
+			case c == '"':
+				inString = !inString
+				header += string(c)
+			case inString:
+				header += string(c)
+			case c == '{':
+				label := strings.Join(strings.Fields(header), " ")
+				if len(blocks) == 1 && blocks[0] == "terraform" && backendBlockPattern.MatchString(label) {
+					return true
+				}
+				blocks = append(blocks, label)
+				header = ""
+			case c == '}':
+				if len(blocks) > 0 {
+					blocks = blocks[:len(blocks)-1]
+				}
+				header = ""
+			default:
+				header += string(c)
+			}
+		}
+	}
+	return false
+}
+
+// stateLocationKeys are the backend settings that identify where a state is stored.
+// Settings of nested blocks are named block.setting, e.g. workspaces.name.
+var stateLocationKeys = map[string]bool{
+	"bucket":               true,
+	"key":                  true,
+	"prefix":               true,
+	"path":                 true,
+	"workspace_key_prefix": true,
+	"storage_account_name": true,
+	"container_name":       true,
+	"organization":         true,
+	"workspaces.name":      true,
+	"workspaces.prefix":    true,
+	"workspaces.tags":      true,
+}
+
+// stateKeys are the state location settings naming a single state within a backend; without one
+// of them the state is chosen elsewhere, e.g. in the terraform block or with -backend-config
+var stateKeys = []string{"key", "prefix", "path", "workspaces.name", "workspaces.prefix", "workspaces.tags"}
+
+// IdentifiesState reports whether state location settings name a single state, not only a backend
+func IdentifiesState(location map[string]string) bool {
+	for _, key := range stateKeys {
+		if _, exists := location[key]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractStateLocationFromBackendConfig parses a .tfbackend/.hcl backend config and returns the
+// settings that identify the state location, e.g. bucket and key
+func ExtractStateLocationFromBackendConfig(content string) map[string]string {
+	location := make(map[string]string)
+	var blocks []string
+	for _, line := range strings.Split(content, "\n") {
+		line = stripHCLComment(line)
+
+		// Blocks may open and close on the same line, e.g. workspaces { name = "app" }
+		for line != "" {
+			end := strings.IndexAny(line, "{}")
+			if end < 0 {
+				end = len(line)
+			}
+			segment := strings.TrimSpace(line[:end])
+
+			switch {
+			case end < len(line) && line[end] == '{':
+				blocks = append(blocks, strings.TrimSpace(strings.TrimSuffix(segment, "=")))
+			default:
+				key, value, found := strings.Cut(segment, "=")
+				key = strings.Join(append(append([]string{}, blocks...), strings.TrimSpace(key)), ".")
+				if found && stateLocationKeys[key] {
+					location[key] = strings.Trim(strings.TrimSpace(value), `"'`)
+				}
+				if end < len(line) && len(blocks) > 0 {
+					blocks = blocks[:len(blocks)-1]
+				}
+			}
+
+			if end == len(line) {
+				break
+			}
+			line = line[end+1:]
+		}
+	}
+	return location
+}
+
+// stripHCLComment removes a trailing # or // comment outside of quoted strings
+func stripHCLComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && inString:
+			i++
+		case line[i] == '"':
+			inString = !inString
+		case !inString && line[i] == '#':
+			return line[:i]
+		case !inString && strings.HasPrefix(line[i:], "//"):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// ExtractStateLocationFromJSONBackendConfig returns the state location settings of a JSON backend config
+func ExtractStateLocationFromJSONBackendConfig(content string) (map[string]string, error) {
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		return nil, fmt.Errorf("error parsing JSON backend config: %w", err)
+	}
+
+	location := make(map[string]string)
+	for key, value := range config {
+		// Nested blocks are objects, e.g. "workspaces": {"name": "app"}
+		if block, isBlock := value.(map[string]interface{}); isBlock {
+			for blockKey, blockValue := range block {
+				if stateLocationKeys[key+"."+blockKey] {
+					location[key+"."+blockKey] = fmt.Sprint(blockValue)
+				}
+			}
+			continue
+		}
+		if stateLocationKeys[key] {
+			location[key] = fmt.Sprint(value)
+		}
+	}
+	return location, nil
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasBackendBlock(t *testing.T) {
+	dir := t.TempDir()
+	mainTF := filepath.Join(dir, "main.tf")
+
+	if err := os.WriteFile(mainTF, []byte("resource \"null_resource\" \"this\" {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := HasBackendBlock(dir); err != nil || found {
+		t.Errorf("Expected no backend, got found=%t err=%v", found, err)
+	}
+
+	backend := "terraform {\n  backend \"s3\" {}\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "backend.tf"), []byte(backend), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := HasBackendBlock(dir); err != nil || !found {
+		t.Errorf("Expected backend to be detected, got found=%t err=%v", found, err)
+	}
+
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{`terraform { backend "s3" {} }`, true},
+		{"terraform {\n  required_version = \">= 1.5\"\n  cloud {\n    organization = \"acme\"\n  }\n}\n", true},
+		{"terraform {\n  # backend \"s3\" {}\n}\n", false},
+		{`resource "null_resource" "cloud" { triggers = { cloud = "x" } }`, false},
+		{`locals { backend = "terraform { backend \"s3\" {} }" }`, false},
+	}
+	for _, tt := range tests {
+		if got := configuresBackend(tt.content); got != tt.want {
+			t.Errorf("configuresBackend(%q) = %t, want %t", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestExtractStateLocationFromBackendConfig(t *testing.T) {
+	content := `# prod state
+bucket  = "tf-state"
+key     = "prod/app.tfstate"
+region  = "eu-west-1"
+profile = "prod"
+`
+	location := ExtractStateLocationFromBackendConfig(content)
+	if len(location) != 2 || location["bucket"] != "tf-state" || location["key"] != "prod/app.tfstate" {
+		t.Errorf("Expected only bucket and key, got: %v", location)
+	}
+
+	location, err := ExtractStateLocationFromJSONBackendConfig(`{"bucket": "tf-state", "key": "dev.tfstate", "region": "eu-west-1"}`)
+	if err != nil || len(location) != 2 || location["key"] != "dev.tfstate" {
+		t.Errorf("Expected bucket and key from JSON, got: %v, %v", location, err)
+	}
+
+	// Trailing comments are not part of the value
+	location = ExtractStateLocationFromBackendConfig(`key = "prod.tfstate" # prod state` + "\n" + `path = "a#b" // quoted`)
+	if location["key"] != "prod.tfstate" || location["path"] != "a#b" {
+		t.Errorf("Expected trailing comments to be stripped, got: %v", location)
+	}
+
+	// Remote backends name the state in a nested workspaces block
+	location = ExtractStateLocationFromBackendConfig("organization = \"acme\"\nworkspaces {\n  name = \"app-prod\"\n}\nhostname = \"app.terraform.io\"\n")
+	if len(location) != 2 || location["workspaces.name"] != "app-prod" || !IdentifiesState(location) {
+		t.Errorf("Expected organization and workspaces.name, got: %v", location)
+	}
+	location = ExtractStateLocationFromBackendConfig(`workspaces { prefix = "app-" }`)
+	if location["workspaces.prefix"] != "app-" {
+		t.Errorf("Expected a single-line workspaces block to be parsed, got: %v", location)
+	}
+
+	// A bucket alone does not say which state is used
+	if IdentifiesState(ExtractStateLocationFromBackendConfig(`bucket = "tf-state"`)) {
+		t.Error("Expected a bucket without a key not to identify a state")
+	}
+}
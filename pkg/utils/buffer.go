@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"sync"
+)
+
+// TailBuffer is an output buffer that optionally keeps only the most recent bytes written
+type TailBuffer struct {
+	mu        sync.Mutex
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+// NewTailBuffer creates a buffer retaining at most limit bytes; a limit of 0 or less is unbounded
+func NewTailBuffer(limit int) *TailBuffer {
+	return &TailBuffer{limit: limit}
+}
+
+// Write appends p to the buffer, discarding the oldest data once the limit is exceeded
+func (b *TailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+
+	// Trim lazily once the buffer holds twice the limit to avoid copying on every write
+	if b.limit > 0 && len(b.buf) > 2*b.limit {
+		b.trim()
+	}
+	return len(p), nil
+}
+
+// WriteString appends s to the buffer
+func (b *TailBuffer) WriteString(s string) (int, error) {
+	return b.Write([]byte(s))
+}
+
+// String returns the retained contents of the buffer
+func (b *TailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit > 0 && len(b.buf) > b.limit {
+		b.trim()
+	}
+	return string(b.buf)
+}
+
+// Truncated reports whether any output has been discarded
+func (b *TailBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.truncated || (b.limit > 0 && len(b.buf) > b.limit)
+}
+
+// trim drops everything but the last limit bytes, starting at a line boundary when possible
+func (b *TailBuffer) trim() {
+	tail := b.buf[len(b.buf)-b.limit:]
+	if idx := bytes.IndexByte(tail, '\n'); idx >= 0 && idx < len(tail)-1 {
+		tail = tail[idx+1:]
+	}
+	b.buf = append([]byte(nil), tail...)
+	b.truncated = true
+}
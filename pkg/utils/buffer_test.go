@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTailBufferUnbounded(t *testing.T) {
+	buf := NewTailBuffer(0)
+	for i := 0; i < 100; i++ {
+		buf.WriteString("line\n")
+	}
+
+	if got := len(buf.String()); got != 500 {
+		t.Errorf("Expected 500 bytes retained, got: %d", got)
+	}
+	if buf.Truncated() {
+		t.Error("Expected unbounded buffer not to be truncated")
+	}
+}
+
+func TestTailBufferBounded(t *testing.T) {
+	buf := NewTailBuffer(20)
+	buf.WriteString("first line\n")
+	buf.WriteString("second line\n")
+	buf.WriteString("third line\n")
+
+	output := buf.String()
+	if len(output) > 20 {
+		t.Errorf("Expected at most 20 bytes retained, got: %d", len(output))
+	}
+	if output != "third line\n" {
+		t.Errorf("Expected output to start at a line boundary, got: %q", output)
+	}
+	if !buf.Truncated() {
+		t.Error("Expected bounded buffer to be truncated")
+	}
+	if strings.Contains(output, "first") {
+		t.Error("Expected oldest output to be discarded")
+	}
+}
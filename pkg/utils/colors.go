@@ -1,6 +1,14 @@
 package utils
 
-import "sync"
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
 
 // ANSI color codes for profile differentiation
 const (
@@ -15,43 +23,217 @@ const (
 	ColorBold   = "\033[1m"
 )
 
+// Profile color palettes
+const (
+	PaletteAuto       = "auto"       // The widest palette the terminal advertises
+	PaletteBasic      = "basic"      // The standard ANSI colors, for limited terminals
+	Palette256        = "256"        // Colors of the 256-color cube
+	PaletteTrueColor  = "truecolor"  // 24-bit colors spread around the hue circle
+	PaletteColorBlind = "colorblind" // Okabe-Ito colors, distinguishable with color vision deficiencies
+)
+
+// okabeIto is the color-blind-safe Okabe-Ito palette as RGB, without black
+var okabeIto = [][3]int{
+	{230, 159, 0}, {86, 180, 233}, {0, 158, 115}, {240, 228, 66}, {0, 114, 178}, {213, 94, 0}, {204, 121, 167},
+}
+
+// okabeIto256 approximates okabeIto on 256-color terminals
+var okabeIto256 = []int{178, 74, 36, 227, 25, 166, 175}
+
 // ProfileColorManager manages color assignment for profiles
 type ProfileColorManager struct {
 	profileColorMap map[string]string
+	used            map[string]bool
 	colorMutex      sync.Mutex
 	colors          []string
 }
 
-// NewProfileColorManager creates a new color manager
+// NewProfileColorManager creates a new color manager using the terminal's widest palette
 func NewProfileColorManager() *ProfileColorManager {
+	palette := DetectPalette()
 	return &ProfileColorManager{
 		profileColorMap: make(map[string]string),
-		colors: []string{
-			ColorCyan,
-			ColorYellow,
-			ColorGreen,
-			ColorPurple,
-			ColorBlue,
-			ColorRed,
-			ColorWhite,
-		},
+		used:            make(map[string]bool),
+		colors:          paletteColors(palette, palette),
+	}
+}
+
+// SetPalette switches to a named palette; profiles already colored keep their color
+func (pcm *ProfileColorManager) SetPalette(palette string) error {
+	depth := DetectPalette()
+	switch palette {
+	case PaletteAuto:
+		palette = depth
+	case PaletteBasic, Palette256, PaletteTrueColor, PaletteColorBlind:
+	default:
+		return fmt.Errorf("invalid color palette %q: must be %s, %s, %s, %s or %s",
+			palette, PaletteAuto, PaletteBasic, Palette256, PaletteTrueColor, PaletteColorBlind)
+	}
+
+	pcm.colorMutex.Lock()
+	defer pcm.colorMutex.Unlock()
+	pcm.colors = paletteColors(palette, depth)
+	return nil
+}
+
+// AssignProfiles colors the profiles of a run up front, in sorted order, so profiles whose names
+// hash to the same color are resolved the same way in every run, whichever logs first
+func (pcm *ProfileColorManager) AssignProfiles(profileNames []string) {
+	sorted := append([]string{}, profileNames...)
+	sort.Strings(sorted)
+
+	pcm.colorMutex.Lock()
+	defer pcm.colorMutex.Unlock()
+	for _, profileName := range sorted {
+		pcm.assignColor(profileName)
 	}
 }
 
-// GetProfileColor assigns and returns a consistent color for a profile
+// GetProfileColor returns a profile's color, assigning it on first use if AssignProfiles did not
 func (pcm *ProfileColorManager) GetProfileColor(profileName string) string {
 	pcm.colorMutex.Lock()
 	defer pcm.colorMutex.Unlock()
+	return pcm.assignColor(profileName)
+}
 
+// assignColor returns a profile's cached color or assigns one. The name's hash picks the first
+// color to try, so a profile keeps its color across runs, and the first color not taken by
+// another profile is used, so profiles stay distinct until the palette is exhausted. Taken
+// colors are skipped by a wide stride, since a palette's neighbours look alike.
+func (pcm *ProfileColorManager) assignColor(profileName string) string {
 	// If we already have a color for this profile, return it
 	if color, exists := pcm.profileColorMap[profileName]; exists {
 		return color
 	}
 
-	// Assign a new color based on the number of profiles we've seen
-	colorIndex := len(pcm.profileColorMap) % len(pcm.colors)
-	color := pcm.colors[colorIndex]
+	hash := fnv.New32a()
+	hash.Write([]byte(profileName))
+	start := int(hash.Sum32() % uint32(len(pcm.colors)))
+	color := pcm.colors[start]
+	stride := probeStride(len(pcm.colors))
+	for i := range pcm.colors {
+		if candidate := pcm.colors[(start+i*stride)%len(pcm.colors)]; !pcm.used[candidate] {
+			color = candidate
+			break
+		}
+	}
 	pcm.profileColorMap[profileName] = color
+	pcm.used[color] = true
 
 	return color
 }
+
+// probeStride returns the step between colors tried for a profile: a golden-ratio fraction of
+// the palette, so consecutive tries land far apart, and coprime with its size, so every color
+// is tried once
+func probeStride(size int) int {
+	stride := max(1, int(math.Round(float64(size)*0.382)))
+	for gcd(stride, size) != 1 {
+		stride++
+	}
+	return stride
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// DetectPalette returns the widest palette the terminal advertises through $COLORTERM and $TERM
+func DetectPalette() string {
+	switch colorTerm := strings.ToLower(os.Getenv("COLORTERM")); {
+	case colorTerm == "truecolor" || colorTerm == "24bit":
+		return PaletteTrueColor
+	case strings.Contains(os.Getenv("TERM"), "256color"):
+		return Palette256
+	default:
+		return PaletteBasic
+	}
+}
+
+// paletteColors returns the escape codes of a palette; the color-blind palette is rendered in the
+// terminal's color depth
+func paletteColors(palette, depth string) []string {
+	var colors []string
+	switch palette {
+	case Palette256:
+		// The 6x6x6 cube without its dark and grey entries, which are hard to read
+		for i := 16; i < 232; i++ {
+			r, g, b := (i-16)/36, (i-16)/6%6, (i-16)%6
+			if max(r, g, b) >= 3 && !(r == g && g == b) {
+				colors = append(colors, fmt.Sprintf("\033[38;5;%dm", i))
+			}
+		}
+	case PaletteTrueColor:
+		// Two lightness levels of 36 hues, 10 degrees apart
+		for _, lightness := range []float64{0.6, 0.75} {
+			for hue := 0; hue < 360; hue += 10 {
+				r, g, b := hslToRGB(float64(hue), 0.7, lightness)
+				colors = append(colors, fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b))
+			}
+		}
+	case PaletteColorBlind:
+		switch depth {
+		case PaletteTrueColor:
+			for _, rgb := range okabeIto {
+				colors = append(colors, fmt.Sprintf("\033[38;2;%d;%d;%dm", rgb[0], rgb[1], rgb[2]))
+			}
+		case Palette256:
+			for _, code := range okabeIto256 {
+				colors = append(colors, fmt.Sprintf("\033[38;5;%dm", code))
+			}
+		default:
+			// Without red and green, the pair most often confused
+			colors = []string{ColorBlue, ColorYellow, ColorCyan, ColorPurple, ColorWhite}
+		}
+	default:
+		colors = []string{ColorCyan, ColorYellow, ColorGreen, ColorPurple, ColorBlue, ColorRed, ColorWhite}
+	}
+	return colors
+}
+
+// hslToRGB converts a hue in degrees and a saturation and lightness between 0 and 1 to RGB
+func hslToRGB(hue, saturation, lightness float64) (int, int, int) {
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := lightness - chroma/2
+
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return int(math.Round((r + m) * 255)), int(math.Round((g + m) * 255)), int(math.Round((b + m) * 255))
+}
+
+// ColorSupported reports whether colored output should be used by default.
+// Colors are disabled when NO_COLOR is set or stdout is not a terminal.
+func ColorSupported() bool {
+	if _, exists := os.LookupEnv("NO_COLOR"); exists {
+		return false
+	}
+
+	return StdoutIsTerminal()
+}
+
+// StdoutIsTerminal reports whether stdout is a terminal rather than a pipe or file
+func StdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDetectPalette(t *testing.T) {
+	tests := []struct {
+		colorTerm, term, want string
+	}{
+		{"truecolor", "xterm", PaletteTrueColor},
+		{"24bit", "", PaletteTrueColor},
+		{"", "xterm-256color", Palette256},
+		{"", "xterm", PaletteBasic},
+	}
+	for _, tt := range tests {
+		t.Setenv("COLORTERM", tt.colorTerm)
+		t.Setenv("TERM", tt.term)
+		if got := DetectPalette(); got != tt.want {
+			t.Errorf("DetectPalette() with COLORTERM=%q TERM=%q = %s, want %s", tt.colorTerm, tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestProfileColorsStableAndDistinct(t *testing.T) {
+	t.Setenv("COLORTERM", "")
+	t.Setenv("TERM", "xterm-256color")
+
+	first, second := NewProfileColorManager(), NewProfileColorManager()
+	seen := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("profile-%d", i)
+		color := first.GetProfileColor(name)
+		if other, exists := seen[color]; exists {
+			t.Errorf("Expected distinct colors, %s and %s share %q", other, name, color)
+		}
+		seen[color] = name
+	}
+	// Colors follow the name, not the order in which profiles are seen
+	if first.GetProfileColor("profile-7") != second.GetProfileColor("profile-7") {
+		t.Error("Expected the same profile to get the same color across runs")
+	}
+
+	if err := second.SetPalette(PaletteColorBlind); err != nil {
+		t.Fatalf("Expected colorblind palette to be valid, got: %v", err)
+	}
+	if len(second.colors) != len(okabeIto256) {
+		t.Errorf("Expected the Okabe-Ito palette, got %d colors", len(second.colors))
+	}
+	if err := second.SetPalette("rainbow"); err == nil {
+		t.Error("Expected an error for an unknown palette")
+	}
+}
+
+func TestAssignProfilesIgnoresOrder(t *testing.T) {
+	t.Setenv("COLORTERM", "")
+	t.Setenv("TERM", "xterm")
+
+	// More profiles than the 7 basic colors, so names are bound to collide
+	var names []string
+	for i := 0; i < 10; i++ {
+		names = append(names, fmt.Sprintf("env-%d", i))
+	}
+	reversed := make([]string, len(names))
+	for i, name := range names {
+		reversed[len(names)-1-i] = name
+	}
+
+	first, second := NewProfileColorManager(), NewProfileColorManager()
+	first.AssignProfiles(names)
+	second.AssignProfiles(reversed)
+	for i := len(names) - 1; i >= 0; i-- {
+		if first.GetProfileColor(names[i]) != second.GetProfileColor(names[i]) {
+			t.Errorf("Expected %s to get the same color whatever the order", names[i])
+		}
+	}
+}
+
+func TestProbeStrideVisitsEveryColorFarApart(t *testing.T) {
+	for _, size := range []int{1, 5, 7, 72, 176} {
+		stride := probeStride(size)
+		if size > 2 && (stride == 1 || stride == size-1) {
+			t.Errorf("Expected a stride skipping neighbouring colors for %d colors, got %d", size, stride)
+		}
+		visited := make(map[int]bool)
+		for i := 0; i < size; i++ {
+			visited[i*stride%size] = true
+		}
+		if len(visited) != size {
+			t.Errorf("Expected stride %d to try all %d colors, tried %d", stride, size, len(visited))
+		}
+	}
+}
@@ -13,11 +13,24 @@ func IsActiveDir() {
 		fmt.Fprintf(os.Stderr, "Error: error occurred while getting working dir: %v\n", err)
 		os.Exit(1)
 	}
-	entries, err := os.ReadDir(dir)
+	active, err := HasActiveFiles(dir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: error occurred while reading module directory: %v\n", err)
 		os.Exit(1)
 	}
+	if active {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: Current directory does not contain any active terraform files\n")
+	os.Exit(1)
+}
+
+// HasActiveFiles reports whether dir contains at least one active terraform file
+func HasActiveFiles(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
 
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -26,11 +39,10 @@ func IsActiveDir() {
 
 		name := entry.Name()
 		if isActiveFile(name) {
-			return
+			return true, nil
 		}
 	}
-	fmt.Fprintf(os.Stderr, "Error: Current directory does not contain any active terraform files\n")
-	os.Exit(1)
+	return false, nil
 }
 
 func isActiveFile(name string) bool {
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.env")
+	os.WriteFile(path, []byte(`# credentials for dev
+AWS_PROFILE=dev-admin
+export TF_VAR_region = "eu-west-1"
+TF_VAR_name='my app'
+
+EMPTY=
+`), 0644)
+
+	env, err := ParseEnvFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := map[string]string{
+		"AWS_PROFILE":   "dev-admin",
+		"TF_VAR_region": "eu-west-1",
+		"TF_VAR_name":   "my app",
+		"EMPTY":         "",
+	}
+	if len(env) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, env)
+	}
+	for key, value := range expected {
+		if env[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, env[key])
+		}
+	}
+
+	os.WriteFile(path, []byte("NOT A VARIABLE\n"), 0644)
+	if _, err := ParseEnvFile(path); err == nil {
+		t.Error("Expected an error for a line without '='")
+	}
+}
@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 )
 
 // CheckFileOrDirExists checks if a file or directory exists, following symlinks
@@ -44,13 +46,14 @@ func ResolveIfSymlink(path string) (string, os.FileInfo, error) {
 		return path, nil, err
 	}
 
-	// If it's a symlink, resolve it
+	// If it's a symlink, resolve it. Stat first, since it reports loops as ELOOP while
+	// EvalSymlinks only returns an untyped error.
 	if info.Mode()&os.ModeSymlink != 0 {
-		resolvedPath, err := filepath.EvalSymlinks(path)
+		resolvedInfo, err := os.Stat(path)
 		if err != nil {
 			return path, info, err
 		}
-		resolvedInfo, err := os.Stat(resolvedPath)
+		resolvedPath, err := filepath.EvalSymlinks(path)
 		if err != nil {
 			return path, info, err
 		}
@@ -72,34 +75,116 @@ func CheckDirExists(dirPath string) (bool, error) {
 	return info.IsDir(), nil
 }
 
-// ScanFilesWithExtension scans a directory for files with the given extension and returns a map of profile names to filenames
+// ScanFilesWithExtension scans a directory for files with the given extension and returns a map of profile names to filenames.
+// Symlinked directories are followed, and symlink cycles are skipped with a warning.
 func ScanFilesWithExtension(dirPath, extension string) (map[string]string, error) {
-	resolvedDir, _, err := ResolveIfSymlink(dirPath)
+	resolvedDir, info, err := ResolveIfSymlink(dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("error resolving directory %s: %w", dirPath, err)
 	}
 
 	files := make(map[string]string)
+	visited := []os.FileInfo{info}
+	err = scanDir(resolvedDir, extension, files, &visited)
 
-	err = filepath.Walk(resolvedDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	return files, err
+}
+
+// scanDir recursively collects files with the given extension, tracking visited directories
+func scanDir(dirPath, extension string, files map[string]string, visited *[]os.FileInfo) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dirPath, entry.Name())
 
 		// Handle symlinks by resolving them
-		_, actualInfo, resolveErr := ResolveIfSymlink(path)
+		resolvedPath, actualInfo, resolveErr := ResolveIfSymlink(path)
 		if resolveErr != nil {
 			// If we can't resolve the symlink, skip it
-			return nil
+			if IsSymlinkLoopError(resolveErr) {
+				fmt.Fprintf(os.Stderr, "Warning: skipping %s: symlink loop detected\n", path)
+			}
+			continue
+		}
+
+		if actualInfo.IsDir() {
+			if containsSameFile(*visited, actualInfo) {
+				fmt.Fprintf(os.Stderr, "Warning: skipping %s: directory already visited (symlink cycle)\n", path)
+				continue
+			}
+			*visited = append(*visited, actualInfo)
+			if err := scanDir(resolvedPath, extension, files, visited); err != nil {
+				return err
+			}
+			continue
 		}
 
-		if !actualInfo.IsDir() && strings.HasSuffix(actualInfo.Name(), extension) {
+		if strings.HasSuffix(actualInfo.Name(), extension) {
 			// Extract profile name (remove extension)
 			profileName := strings.TrimSuffix(actualInfo.Name(), extension)
 			files[profileName] = actualInfo.Name()
 		}
-		return nil
-	})
+	}
+	return nil
+}
 
-	return files, err
+// IsSymlinkCycle reports whether path is a symlink that loops or points back to root or one of its ancestors
+func IsSymlinkCycle(path, root string) bool {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+
+	_, targetInfo, err := ResolveIfSymlink(path)
+	if err != nil {
+		return IsSymlinkLoopError(err)
+	}
+	if !targetInfo.IsDir() {
+		return false
+	}
+
+	for dir := root; ; dir = filepath.Dir(dir) {
+		if dirInfo, err := os.Stat(dir); err == nil && os.SameFile(dirInfo, targetInfo) {
+			return true
+		}
+		if filepath.Dir(dir) == dir {
+			return false
+		}
+	}
+}
+
+// IsSymlinkLoopError reports whether err was caused by too many levels of symlinks
+func IsSymlinkLoopError(err error) bool {
+	return errors.Is(err, syscall.ELOOP)
+}
+
+// containsSameFile reports whether info refers to the same file as any entry in infos
+func containsSameFile(infos []os.FileInfo, info os.FileInfo) bool {
+	for _, candidate := range infos {
+		if candidate != nil && os.SameFile(candidate, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDirWritable verifies that files can be created in the given directory
+func CheckDirWritable(dirPath string) error {
+	f, err := os.CreateTemp(dirPath, ".tapper-write-check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// FileSafeName flattens a nested profile name such as networking/prod into a single path
+// element, for use in file and directory names. Separators are percent-escaped, so
+// distinct names such as a/b and a_b never share a file.
+func FileSafeName(name string) string {
+	return strings.NewReplacer("%", "%25", "/", "%2F", `\`, "%5C").Replace(name)
 }
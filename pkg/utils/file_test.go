@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanFilesWithExtensionSymlinkCycles(t *testing.T) {
+	dir := t.TempDir()
+	backendDir := filepath.Join(dir, "backend")
+	os.MkdirAll(filepath.Join(backendDir, "nested"), 0755)
+	os.WriteFile(filepath.Join(backendDir, "dev.tfbackend"), []byte(""), 0644)
+	os.WriteFile(filepath.Join(backendDir, "nested", "prod.tfbackend"), []byte(""), 0644)
+
+	// A link back to the scanned directory and a self-referencing loop
+	if err := os.Symlink("..", filepath.Join(backendDir, "nested", "parent")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+	os.Symlink("loop", filepath.Join(backendDir, "loop"))
+
+	files, err := ScanFilesWithExtension(backendDir, ".tfbackend")
+	if err != nil {
+		t.Fatalf("Expected no error scanning directory with cycles, got: %v", err)
+	}
+	if len(files) != 2 || files["dev"] == "" || files["prod"] == "" {
+		t.Errorf("Expected dev and prod backends, got: %v", files)
+	}
+
+	if !IsSymlinkCycle(filepath.Join(backendDir, "nested", "parent"), filepath.Join(backendDir, "nested")) {
+		t.Error("Expected link to parent directory to be a cycle")
+	}
+	if !IsSymlinkCycle(filepath.Join(backendDir, "loop"), backendDir) {
+		t.Error("Expected self-referencing link to be a cycle")
+	}
+	if IsSymlinkCycle(filepath.Join(backendDir, "dev.tfbackend"), backendDir) {
+		t.Error("Expected regular file not to be a cycle")
+	}
+}
+
+func TestFileSafeNameKeepsNamesDistinct(t *testing.T) {
+	names := []string{"networking/prod", "networking_prod", `networking\prod`, "networking%2Fprod"}
+	seen := make(map[string]string)
+	for _, name := range names {
+		safe := FileSafeName(name)
+		if filepath.Base(safe) != safe {
+			t.Errorf("Expected %q to flatten to a single path element, got %q", name, safe)
+		}
+		if other, ok := seen[safe]; ok {
+			t.Errorf("Expected %q and %q to get distinct names, both got %q", name, other, safe)
+		}
+		seen[safe] = name
+	}
+}
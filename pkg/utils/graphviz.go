@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ErrNoGraphviz is returned when graphviz's dot executable cannot be found
+var ErrNoGraphviz = errors.New("graphviz (dot) is not installed")
+
+// RenderDOT renders a DOT graph to outputFile with graphviz, in the given format, e.g. png
+func RenderDOT(graph []byte, format, outputFile string) error {
+	path, err := exec.LookPath("dot")
+	if err != nil {
+		return ErrNoGraphviz
+	}
+
+	cmd := exec.Command(path, "-T"+format, "-o", outputFile)
+	cmd.Stdin = bytes.NewReader(graph)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error rendering graph to %s: %w", outputFile, err)
+	}
+	return nil
+}
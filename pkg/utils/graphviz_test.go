@@ -0,0 +1,10 @@
+package utils
+
+import "testing"
+
+func TestRenderDOTWithoutGraphviz(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if err := RenderDOT([]byte("digraph {}"), "png", "graph.png"); err != ErrNoGraphviz {
+		t.Errorf("RenderDOT() error = %v, want ErrNoGraphviz", err)
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrNonInteractive is returned when input is required but stdin is not a terminal
+var ErrNonInteractive = errors.New("stdin is not a terminal")
+
+var (
+	stdinOnce  sync.Once
+	stdinLines chan string
+
+	unreadMutex sync.Mutex
+	unread      []string // Lines handed back with UnreadLine, returned first by ReadLine
+)
+
+// StdinLines returns a channel of lines read from stdin by a single shared reader.
+// Sharing one reader lets prompts and live output controls consume input without losing buffered data.
+func StdinLines() <-chan string {
+	stdinOnce.Do(func() {
+		stdinLines = make(chan string)
+		go func() {
+			reader := bufio.NewReader(os.Stdin)
+			for {
+				line, err := reader.ReadString('\n')
+				if line != "" {
+					stdinLines <- line
+				}
+				if err != nil {
+					close(stdinLines)
+					return
+				}
+			}
+		}()
+	})
+	return stdinLines
+}
+
+// UnreadLine hands a line taken from StdinLines back, so the next ReadLine returns it
+func UnreadLine(line string) {
+	unreadMutex.Lock()
+	defer unreadMutex.Unlock()
+	unread = append(unread, line)
+}
+
+// ReadLine reads the next line from stdin through the shared reader
+func ReadLine() (string, error) {
+	unreadMutex.Lock()
+	if len(unread) > 0 {
+		line := unread[0]
+		unread = unread[1:]
+		unreadMutex.Unlock()
+		return line, nil
+	}
+	unreadMutex.Unlock()
+
+	line, ok := <-StdinLines()
+	if !ok {
+		return "", io.EOF
+	}
+	return line, nil
+}
+
+// IsInteractive reports whether stdin is a terminal that can answer prompts
+func IsInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
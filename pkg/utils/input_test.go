@@ -0,0 +1,14 @@
+package utils
+
+import "testing"
+
+func TestUnreadLine(t *testing.T) {
+	UnreadLine("y\n")
+	UnreadLine("n\n")
+
+	for _, want := range []string{"y\n", "n\n"} {
+		if line, err := ReadLine(); err != nil || line != want {
+			t.Errorf("ReadLine() = %q, %v, want the unread line %q", line, err, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseProviderLock reads a .terraform.lock.hcl file and returns provider addresses mapped to their locked versions
+func ParseProviderLock(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	versions := make(map[string]string)
+	provider := ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "provider "):
+			// provider "registry.terraform.io/hashicorp/aws" {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				provider = strings.Trim(fields[1], `"`)
+			}
+		case provider != "" && strings.HasPrefix(line, "version"):
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) == 2 {
+				versions[provider] = strings.Trim(strings.TrimSpace(parts[1]), `"`)
+			}
+		case line == "}":
+			provider = ""
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading lock file %s: %w", path, err)
+	}
+	return versions, nil
+}
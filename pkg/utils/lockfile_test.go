@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProviderLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".terraform.lock.hcl")
+	lock := `# This file is maintained automatically by "terraform init".
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+}
+`
+	if err := os.WriteFile(path, []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := ParseProviderLock(path)
+	if err != nil {
+		t.Fatalf("Expected lock file to parse, got: %v", err)
+	}
+	if len(versions) != 2 || versions["registry.terraform.io/hashicorp/aws"] != "5.31.0" ||
+		versions["registry.terraform.io/hashicorp/random"] != "3.6.0" {
+		t.Errorf("Unexpected provider versions: %v", versions)
+	}
+
+	if _, err := ParseProviderLock(filepath.Join(t.TempDir(), "missing.hcl")); err == nil {
+		t.Error("Expected an error for a missing lock file")
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultPager is used when $PAGER is unset; -R keeps terraform's colors
+const DefaultPager = "less -R"
+
+// ErrNoPager is returned when content cannot be shown in a pager
+var ErrNoPager = errors.New("no pager available")
+
+// PagerCommand returns the pager command line from $PAGER, falling back to DefaultPager
+func PagerCommand() []string {
+	if fields := strings.Fields(os.Getenv("PAGER")); len(fields) > 0 {
+		return fields
+	}
+	return strings.Fields(DefaultPager)
+}
+
+// Page shows content in the user's pager and waits for it to exit.
+// It returns ErrNoPager when stdout is not a terminal or the pager cannot be found.
+func Page(content string) error {
+	if !StdoutIsTerminal() {
+		return ErrNoPager
+	}
+	args := PagerCommand()
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return ErrNoPager
+	}
+
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		// Let less pass through colors even when $PAGER is a bare "less"
+		cmd.Env = append(cmd.Env, "LESS=R")
+	}
+	return cmd.Run()
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("PAGER", "")
+	if got := PagerCommand(); !reflect.DeepEqual(got, []string{"less", "-R"}) {
+		t.Errorf("PagerCommand() = %v, want default less -R", got)
+	}
+
+	t.Setenv("PAGER", "most -s")
+	if got := PagerCommand(); !reflect.DeepEqual(got, []string{"most", "-s"}) {
+		t.Errorf("PagerCommand() = %v, want [most -s]", got)
+	}
+
+	// Tests do not run on a terminal, so content is never paged
+	if err := Page("plan output"); err != ErrNoPager {
+		t.Errorf("Page() error = %v, want ErrNoPager", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -18,8 +19,14 @@ type SelectionConfig struct {
 	Border        bool
 	Reverse       bool
 	Multi         bool
-	Preview       string
+	Preview       string // fzf preview command; the fallback selector shows no preview
 	PreviewWindow string
+	Filter        string // Regular expression narrowing the items before they are offered
+}
+
+// ShellQuote quotes s as a single shell word for fzf preview commands
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // DefaultSingleSelectConfig returns default config for single selection
@@ -46,17 +53,50 @@ func DefaultMultiSelectConfig(prompt, header string) SelectionConfig {
 	}
 }
 
+// FilterItems returns the items matching the regular expression pattern, or all of them when
+// pattern is empty
+func FilterItems(items []string, pattern string) ([]string, error) {
+	if pattern == "" {
+		return items, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %w", pattern, err)
+	}
+
+	var matched []string
+	for _, item := range items {
+		if re.MatchString(item) {
+			matched = append(matched, item)
+		}
+	}
+	return matched, nil
+}
+
 // InteractiveSelect provides unified fzf-based selection with fallback
 func InteractiveSelect(items []string, config SelectionConfig) ([]string, error) {
 	if len(items) == 0 {
 		return nil, fmt.Errorf("no items provided for selection")
 	}
 
+	items, err := FilterItems(items, config.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("nothing matches the filter %q", config.Filter)
+	}
+
 	if len(items) == 1 && !config.Multi {
 		fmt.Printf("Using the only available option: %s\n", items[0])
 		return []string{items[0]}, nil
 	}
 
+	// Never wait for a selection that cannot be made
+	if !IsInteractive() {
+		return nil, fmt.Errorf("cannot prompt for a selection: %w; pass the profile names as arguments", ErrNonInteractive)
+	}
+
 	// Check if fzf is available
 	if _, err := exec.LookPath("fzf"); err != nil {
 		// Fallback to simple selection if fzf is not available
@@ -185,12 +225,16 @@ func fallbackSelect(items []string, config SelectionConfig) ([]string, error) {
 
 // handleSingleSelectInput handles single selection input parsing
 func handleSingleSelectInput(items []string) ([]string, error) {
-	var selection int
-	_, err := fmt.Scanln(&selection)
+	input, err := ReadLine()
 	if err != nil {
 		return nil, fmt.Errorf("error reading input: %w", err)
 	}
 
+	selection, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil {
+		return nil, fmt.Errorf("invalid selection '%s': must be a number", strings.TrimSpace(input))
+	}
+
 	if selection < 1 || selection > len(items) {
 		return nil, fmt.Errorf("invalid selection. Please enter a number between 1 and %d", len(items))
 	}
@@ -200,8 +244,7 @@ func handleSingleSelectInput(items []string) ([]string, error) {
 
 // handleMultiSelectInput handles multi-selection input parsing
 func handleMultiSelectInput(items []string) ([]string, error) {
-	var input string
-	_, err := fmt.Scanln(&input)
+	input, err := ReadLine()
 	if err != nil {
 		return nil, fmt.Errorf("error reading input: %w", err)
 	}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	for _, value := range []string{"vars", "my vars", "it's", "$HOME"} {
+		output, err := exec.Command("sh", "-c", "printf %s "+ShellQuote(value)).Output()
+		if err != nil {
+			t.Fatalf("sh failed: %v", err)
+		}
+		if string(output) != value {
+			t.Errorf("Expected %q to survive the shell, got %q", value, output)
+		}
+	}
+}
+
+func TestInteractiveSelectFilter(t *testing.T) {
+	items := []string{"dev", "prod-eu", "prod-us", "staging"}
+	if got, _ := FilterItems(items, "^prod-"); len(got) != 2 || got[0] != "prod-eu" || got[1] != "prod-us" {
+		t.Errorf("FilterItems() = %v, want the prod profiles", got)
+	}
+
+	// A single match is selected without prompting, like a single item
+	config := DefaultSingleSelectConfig("Select: ", "Profiles")
+	config.Filter = "stag"
+	if got, err := InteractiveSelect(items, config); err != nil || len(got) != 1 || got[0] != "staging" {
+		t.Errorf("InteractiveSelect() = %v, %v, want [staging]", got, err)
+	}
+
+	config.Filter = "prod-("
+	if _, err := InteractiveSelect(items, config); err == nil || !strings.Contains(err.Error(), "invalid filter") {
+		t.Errorf("Expected an invalid filter error, got: %v", err)
+	}
+	config.Filter = "qa"
+	if _, err := InteractiveSelect(items, config); err == nil {
+		t.Error("Expected an error when nothing matches")
+	}
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ValidateTFVars performs a lightweight syntax check of a .tfvars or .tfvars.json file.
+// Errors are reported as "path:line: message" so they can be traced back to the file.
+func ValidateTFVars(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading var file %s: %w", path, err)
+	}
+
+	if strings.TrimSpace(string(data)) == "" {
+		return fmt.Errorf("%s: var file is empty", path)
+	}
+
+	if strings.HasSuffix(path, ".json") {
+		var values map[string]interface{}
+		if err := json.Unmarshal(data, &values); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line := 1 + bytes.Count(data[:syntaxErr.Offset], []byte("\n"))
+				return fmt.Errorf("%s:%d: %v", path, line, err)
+			}
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		return nil
+	}
+
+	if line, err := checkTFVarsSyntax(string(data)); err != nil {
+		return fmt.Errorf("%s:%d: %v", path, line, err)
+	}
+	return nil
+}
+
+// bracket records an opening bracket and the line it appeared on
+type bracket struct {
+	char rune
+	line int
+}
+
+// checkTFVarsSyntax validates that content is a sequence of "name = value" assignments, one per
+// line, with balanced brackets, terminated strings and heredocs. It returns the offending line on error.
+func checkTFVarsSyntax(content string) (int, error) {
+	runes := []rune(content)
+	closers := map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+	// Lines are looked up from the position, so skipping ahead (e.g. over heredocs) cannot skew them
+	var newlines []int
+	for i, r := range runes {
+		if r == '\n' {
+			newlines = append(newlines, i)
+		}
+	}
+	lineAt := func(pos int) int {
+		return 1 + sort.SearchInts(newlines, pos)
+	}
+
+	var stack []bracket
+	inAssignment := false
+	haveValue := false
+	name := ""
+
+	// A top-level value followed by whitespace and another operand, e.g. a = "x" b = 2,
+	// means a second assignment or stray tokens on the same line
+	operandEnded := false
+	afterSpace := false
+	startOperand := func(pos int) error {
+		if inAssignment && len(stack) == 0 && operandEnded && afterSpace {
+			return fmt.Errorf("unexpected %q after the value of %q", string(runes[pos]), name)
+		}
+		return nil
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		switch {
+		case c == '\n':
+			if len(stack) == 0 && inAssignment {
+				if !haveValue {
+					return lineAt(i), fmt.Errorf("missing value for %q", name)
+				}
+				inAssignment = false
+			}
+
+		case c == ' ' || c == '\t' || c == '\r':
+			afterSpace = true
+
+		case c == '#' || (c == '/' && i+1 < len(runes) && runes[i+1] == '/'):
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start := i
+			for i += 2; i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/'); i++ {
+			}
+			if i >= len(runes) {
+				return lineAt(start), fmt.Errorf("unterminated comment")
+			}
+			i++
+			afterSpace = true
+
+		case len(stack) == 0 && !inAssignment:
+			// A new top-level statement must be an assignment
+			start := i
+			for i < len(runes) && isIdentifierRune(runes[i], i == start) {
+				i++
+			}
+			if i == start {
+				return lineAt(i), fmt.Errorf("expected variable assignment, found %q", string(c))
+			}
+			name = string(runes[start:i])
+			for i < len(runes) && (runes[i] == ' ' || runes[i] == '\t') {
+				i++
+			}
+			if i >= len(runes) || runes[i] != '=' || (i+1 < len(runes) && runes[i+1] == '=') {
+				return lineAt(start), fmt.Errorf("expected '=' after %q", name)
+			}
+			inAssignment = true
+			haveValue = false
+			operandEnded = false
+
+		case c == '"':
+			if err := startOperand(i); err != nil {
+				return lineAt(i), err
+			}
+			start := i
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				} else if runes[i] == '\n' {
+					return lineAt(start), fmt.Errorf("unterminated string")
+				}
+			}
+			if i >= len(runes) {
+				return lineAt(start), fmt.Errorf("unterminated string")
+			}
+			haveValue = true
+			operandEnded, afterSpace = true, false
+
+		case c == '<' && i+1 < len(runes) && runes[i+1] == '<':
+			if err := startOperand(i); err != nil {
+				return lineAt(i), err
+			}
+			start := i
+			end := strings.IndexRune(string(runes[i:]), '\n')
+			if end < 0 {
+				return lineAt(start), fmt.Errorf("unterminated heredoc")
+			}
+			marker := strings.TrimSpace(strings.TrimLeft(string(runes[i+2:i+end]), "-"))
+			if marker == "" {
+				return lineAt(start), fmt.Errorf("missing heredoc marker")
+			}
+			i += end
+			terminated := false
+			for i < len(runes) && !terminated {
+				next := strings.IndexRune(string(runes[i+1:]), '\n')
+				var current string
+				if next < 0 {
+					current = string(runes[i+1:])
+					i = len(runes)
+				} else {
+					current = string(runes[i+1 : i+1+next])
+					i += next + 1
+				}
+				terminated = strings.TrimSpace(current) == marker
+			}
+			if !terminated {
+				return lineAt(start), fmt.Errorf("unterminated heredoc %q", marker)
+			}
+			// Step back so the newline ending the marker is processed normally
+			if i < len(runes) {
+				i--
+			}
+			haveValue = true
+			operandEnded, afterSpace = true, false
+
+		case c == '(' || c == '[' || c == '{':
+			if err := startOperand(i); err != nil {
+				return lineAt(i), err
+			}
+			stack = append(stack, bracket{char: c, line: lineAt(i)})
+			haveValue = true
+
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) == 0 || stack[len(stack)-1].char != closers[c] {
+				return lineAt(i), fmt.Errorf("unexpected %q", string(c))
+			}
+			stack = stack[:len(stack)-1]
+			operandEnded, afterSpace = true, false
+
+		default:
+			if (isIdentifierRune(c, false) && c != '-') || c == '.' {
+				if err := startOperand(i); err != nil {
+					return lineAt(i), err
+				}
+				operandEnded = true
+			} else {
+				// Operators join operands, e.g. a = 1 + 2
+				operandEnded = false
+			}
+			afterSpace = false
+			haveValue = true
+		}
+	}
+
+	if len(stack) > 0 {
+		open := stack[len(stack)-1]
+		return open.line, fmt.Errorf("unclosed %q", string(open.char))
+	}
+	if inAssignment && !haveValue {
+		return lineAt(len(runes)), fmt.Errorf("missing value for %q", name)
+	}
+	return 0, nil
+}
+
+// isIdentifierRune reports whether r can appear in an HCL identifier
+func isIdentifierRune(r rune, first bool) bool {
+	if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		return true
+	}
+	return !first && (r == '-' || (r >= '0' && r <= '9'))
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateTFVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{"valid", "dev.tfvars", "environment = \"dev\"\ncount = 2\n", ""},
+		{"valid nested", "dev.tfvars", "tags = {\n  Name = \"dev\" # comment\n}\nlist = [\"a\",\n \"b\"]\n", ""},
+		{"valid heredoc", "dev.tfvars", "policy = <<EOT\n{ unbalanced\nEOT\nname = \"x\"\n", ""},
+		{"empty", "dev.tfvars", "  \n", "var file is empty"},
+		{"missing equals", "dev.tfvars", "environment \"dev\"\n", "dev.tfvars:1: expected '='"},
+		{"missing value", "dev.tfvars", "a = 1\nenvironment =\n", "dev.tfvars:2: missing value"},
+		{"unclosed brace", "dev.tfvars", "a = 1\ntags = {\n  Name = \"dev\"\n", "dev.tfvars:2: unclosed \"{\""},
+		{"unterminated string", "dev.tfvars", "a = \"dev\nb = 1\n", "dev.tfvars:1: unterminated string"},
+		{"valid expression", "dev.tfvars", "count = 1 - 2\nenabled = true ? \"a\" : \"b\"\nmax = -1\n", ""},
+		{"line after heredoc", "dev.tfvars", "policy = <<EOT\nx\nEOT\nname =\n", "dev.tfvars:4: missing value"},
+		{"two assignments on a line", "dev.tfvars", "a = \"x\" b = 2\n", "dev.tfvars:1: unexpected \"b\""},
+		{"stray value", "dev.tfvars", "a = 1\ntags = {} [1]\n", "dev.tfvars:2: unexpected \"[\""},
+		{"valid json", "dev.tfvars.json", "{\"environment\": \"dev\"}", ""},
+		{"invalid json", "dev.tfvars.json", "{\n\"environment\": \"dev\",\n}", "dev.tfvars.json:3:"},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write var file: %v", err)
+			}
+
+			err := ValidateTFVars(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
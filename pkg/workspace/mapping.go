@@ -0,0 +1,40 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Mapping describes the workspaces created for an operation, for use by external tooling
+type Mapping struct {
+	OperationID string            `json:"operation_id"`
+	ModuleDir   string            `json:"module_dir"`
+	Workspaces  map[string]string `json:"workspaces"` // profile name -> workspace path
+}
+
+// Mapping returns the profile-to-workspace mapping of the current operation
+func (wm *WorkspaceManager) Mapping() Mapping {
+	wm.spacesMutex.Lock()
+	defer wm.spacesMutex.Unlock()
+	workspaces := make(map[string]string, len(wm.ProfileSpaces))
+	for profile, path := range wm.ProfileSpaces {
+		workspaces[profile] = path
+	}
+
+	return Mapping{
+		OperationID: wm.OperationID,
+		ModuleDir:   wm.BaseDirPath,
+		Workspaces:  workspaces,
+	}
+}
+
+// WriteMapping writes the profile-to-workspace mapping as indented JSON
+func (wm *WorkspaceManager) WriteMapping(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(wm.Mapping()); err != nil {
+		return fmt.Errorf("error encoding workspace mapping: %w", err)
+	}
+	return nil
+}
@@ -2,25 +2,59 @@ package workspace
 
 import (
 	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+
+	"tapper/pkg/utils"
+)
+
+// InitMarkerFile records the inputs of the last terraform init inside a .terraform directory
+const InitMarkerFile = ".tapper-init"
+
+// PluginCacheEnv names a persistent provider cache directory shared across runs
+const PluginCacheEnv = "TAPPER_PLUGIN_CACHE_DIR"
+
+// pluginCacheName is the workspace-style name of the per-run provider cache, removed by Cleanup
+const pluginCacheName = "tapper-plugin-cache"
+
+// Workspace modes control how the module is mirrored into each profile workspace
+const (
+	ModeSymlink = "symlink" // Link every module entry into the workspace
+	ModeCopy    = "copy"    // Copy the module, for filesystems without symlink support
 )
 
+// DefaultIgnorePatterns are top-level module entries left out of every workspace: version control,
+// state backups, editor temp files and tapper's own state directory
+var DefaultIgnorePatterns = []string{".git", "*.backup", "*~", "*.swp", "*.swo", "#*#", ".DS_Store", ".tapper"}
+
 // Profile represents a simplified profile for workspace operations
 type Profile struct {
 	Name string
+	Dir  string // Module directory relative to BaseDirPath, empty for the base module itself
 }
 
 // WorkspaceManager handles creating and managing temporary workspaces for multi-profile execution
 type WorkspaceManager struct {
-	BaseDirPath   string
-	OperationID   string            // Unique ID for this operation
-	ProfileSpaces map[string]string // profile name -> workspace path
+	BaseDirPath     string
+	WorkspaceParent string            // Directory in which workspaces are created
+	OperationID     string            // Unique ID for this operation
+	ProfileSpaces   map[string]string // profile name -> workspace path
+	Mode            string            // ModeSymlink or ModeCopy
+	IsolatedDirs    []string          // Top-level module directories mirrored with per-file symlinks instead of linked whole
+	IgnorePatterns  []string          // Top-level module entries matching these patterns are left out of workspaces
+	PluginCacheDir  string            // Provider cache shared by every init of this operation, set by CreatePluginCache
+	PersistentCache bool              // Whether PluginCacheDir outlives this operation
+	spacesMutex     sync.Mutex        // Guards ProfileSpaces while workspaces are created in parallel
 }
 
-func NewWorkspaceManager() (*WorkspaceManager, error) {
+// NewWorkspaceManager creates a workspace manager for the module in baseDir
+func NewWorkspaceManager(baseDir string) (*WorkspaceManager, error) {
 	bytes := make([]byte, 4) // 4 bytes = 8 hex characters
 	_, err := rand.Read(bytes)
 	if err != nil {
@@ -28,48 +62,232 @@ func NewWorkspaceManager() (*WorkspaceManager, error) {
 	}
 	operationID := fmt.Sprintf("%x", bytes)
 
-	cwd, err := os.Getwd()
+	baseDir, err = filepath.Abs(baseDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
+		return nil, fmt.Errorf("failed to resolve module directory: %w", err)
 	}
 
 	return &WorkspaceManager{
-		BaseDirPath:   cwd,
-		OperationID:   operationID,
-		ProfileSpaces: make(map[string]string),
+		BaseDirPath:     baseDir,
+		WorkspaceParent: filepath.Dir(baseDir),
+		OperationID:     operationID,
+		ProfileSpaces:   make(map[string]string),
+		Mode:            ModeSymlink,
+		IgnorePatterns:  DefaultIgnorePatterns,
 	}, nil
 }
 
-func (wm *WorkspaceManager) CreateWorkspaces(profiles []Profile) error {
-	workspaceParent := filepath.Dir(wm.BaseDirPath)
+// SetWorkspaceParent relocates workspaces to dir, e.g. a fast local disk when the module is on a network mount
+func (wm *WorkspaceManager) SetWorkspaceParent(dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("error resolving workspace directory %s: %w", dir, err)
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return fmt.Errorf("error creating workspace directory %s: %w", absDir, err)
+	}
+	realDir, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return fmt.Errorf("error resolving workspace directory %s: %w", absDir, err)
+	}
+	wm.WorkspaceParent = realDir
+	return nil
+}
+
+// SetMode selects how workspaces mirror the module: ModeSymlink or ModeCopy
+func (wm *WorkspaceManager) SetMode(mode string) error {
+	switch mode {
+	case ModeSymlink, ModeCopy:
+		wm.Mode = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid workspace mode %q, expected %s or %s", mode, ModeSymlink, ModeCopy)
+	}
+}
+
+// AddIgnorePatterns extends IgnorePatterns with filepath.Match patterns for top-level module entries
+func (wm *WorkspaceManager) AddIgnorePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			return fmt.Errorf("invalid workspace ignore pattern %q: only top-level entries can be ignored", pattern)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid workspace ignore pattern %q: %w", pattern, err)
+		}
+	}
+	wm.IgnorePatterns = append(append([]string{}, wm.IgnorePatterns...), patterns...)
+	return nil
+}
+
+// isIgnored reports whether the top-level module entry name matches an ignore pattern or is a
+// workspace left behind by an earlier run, e.g. with --keep-workspaces
+func (wm *WorkspaceManager) isIgnored(name string) bool {
+	if name == ".terraform" {
+		return false
+	}
+	if wm.isWorkspaceName(name) {
+		return true
+	}
+	for _, pattern := range wm.IgnorePatterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isWorkspaceName reports whether name follows tapper's workspace naming, .<base>-<name>-<operation ID>
+func (wm *WorkspaceManager) isWorkspaceName(name string) bool {
+	prefix := fmt.Sprintf(".%s-", filepath.Base(wm.BaseDirPath))
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	i := strings.LastIndex(name, "-")
+	if i < len(prefix) {
+		return false
+	}
+	operationID := name[i+1:]
+	_, err := hex.DecodeString(operationID)
+	return len(operationID) == 8 && err == nil
+}
+
+// CreatePluginCache prepares the provider cache directory used by every init of this operation.
+// TAPPER_PLUGIN_CACHE_DIR, or an already set TF_PLUGIN_CACHE_DIR, selects a persistent cache that
+// is kept across runs; otherwise a per-run directory next to the workspaces is removed by Cleanup.
+func (wm *WorkspaceManager) CreatePluginCache() (string, error) {
+	if wm.PluginCacheDir != "" {
+		return wm.PluginCacheDir, nil
+	}
+
+	cacheDir := os.Getenv(PluginCacheEnv)
+	if cacheDir == "" {
+		cacheDir = os.Getenv("TF_PLUGIN_CACHE_DIR")
+	}
+	wm.PersistentCache = cacheDir != ""
+	if cacheDir == "" {
+		cacheName := fmt.Sprintf(".%s-%s-%s", filepath.Base(wm.BaseDirPath), pluginCacheName, wm.OperationID)
+		cacheDir = filepath.Join(wm.WorkspaceParent, cacheName)
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("error creating plugin cache directory %s: %w", cacheDir, err)
+	}
+	absDir, err := filepath.Abs(cacheDir)
+	if err != nil {
+		return "", fmt.Errorf("error resolving plugin cache directory %s: %w", cacheDir, err)
+	}
+	wm.PluginCacheDir = absDir
+	return absDir, nil
+}
+
+// CreateWorkspaces creates the profile workspaces, at most concurrency at a time.
+// Every profile is attempted; the errors of all failed profiles are returned together.
+func (wm *WorkspaceManager) CreateWorkspaces(profiles []Profile, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+	errs := make([]error, len(profiles))
+	var wg sync.WaitGroup
 
-	for _, profile := range profiles {
-		// Create profile-specific workspace directory alongside BaseDir
-		// Pattern: .dir-<PROFILE>-<OPERATION_ID>
+	for i, profile := range profiles {
+		wg.Add(1)
+		go func(i int, profile Profile) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
 
-		baseDir := filepath.Base(wm.BaseDirPath)
-		profileWorkspaceName := fmt.Sprintf(".%s-%s-%s", baseDir, profile.Name, wm.OperationID)
-		profileWorkspace := filepath.Join(workspaceParent, profileWorkspaceName)
+			errs[i] = wm.createWorkspace(profile)
+		}(i, profile)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
 
-		if err := os.MkdirAll(profileWorkspace, 0755); err != nil {
-			return fmt.Errorf("error creating profile workspace %s: %w", profileWorkspace, err)
+// createWorkspace creates and populates a single profile's workspace
+func (wm *WorkspaceManager) createWorkspace(profile Profile) error {
+	// Create profile-specific workspace directory alongside BaseDir
+	// Pattern: .dir-<PROFILE>-<OPERATION_ID>
+	baseDir := filepath.Base(wm.BaseDirPath)
+	profileWorkspaceName := fmt.Sprintf(".%s-%s-%s", baseDir, utils.FileSafeName(profile.Name), wm.OperationID)
+	profileWorkspace := filepath.Join(wm.WorkspaceParent, profileWorkspaceName)
+
+	if err := os.MkdirAll(profileWorkspace, 0755); err != nil {
+		return fmt.Errorf("error creating profile workspace %s: %w", profileWorkspace, err)
+	}
+
+	// A nested module keeps its place in the tree, so relative module sources still resolve
+	sourceDir, moduleWorkspace := wm.BaseDirPath, profileWorkspace
+	if profile.Dir != "" {
+		components := strings.Split(filepath.ToSlash(filepath.Clean(profile.Dir)), "/")
+		var err error
+		if moduleWorkspace, err = wm.mirrorPath(wm.BaseDirPath, profileWorkspace, components); err != nil {
+			return fmt.Errorf("error mirroring module %s for profile %s: %w", profile.Dir, profile.Name, err)
 		}
+		sourceDir = filepath.Join(wm.BaseDirPath, profile.Dir)
+	}
 
-		// Store the mapping
-		wm.ProfileSpaces[profile.Name] = profileWorkspace
+	// Store the mapping
+	wm.spacesMutex.Lock()
+	wm.ProfileSpaces[profile.Name] = moduleWorkspace
+	wm.spacesMutex.Unlock()
 
-		// Create symlinks for all files and directories (including special .terraform handling)
-		if err := wm.symlink(profileWorkspace); err != nil {
-			return fmt.Errorf("error creating symlinks for profile %s: %w", profile.Name, err)
+	if wm.Mode == ModeCopy {
+		if err := wm.copyFiles(sourceDir, moduleWorkspace); err != nil {
+			return fmt.Errorf("error copying files for profile %s: %w", profile.Name, err)
 		}
+		return nil
 	}
 
+	// Create symlinks for all files and directories (including special .terraform handling)
+	if err := wm.symlink(sourceDir, moduleWorkspace); err != nil {
+		return fmt.Errorf("error creating symlinks for profile %s: %w", profile.Name, err)
+	}
 	return nil
 }
 
-// symlink creates symlinks for all files and directories in the base directory
-func (wm *WorkspaceManager) symlink(targetDir string) error {
-	entries, err := os.ReadDir(wm.BaseDirPath)
+// mirrorPath recreates the directories leading from sourceDir to a nested module (its path
+// components) inside targetDir, linking or copying every other entry on the way, and returns
+// the module's directory inside targetDir
+func (wm *WorkspaceManager) mirrorPath(sourceDir, targetDir string, components []string) (string, error) {
+	if len(components) == 0 {
+		return targetDir, nil
+	}
+
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return "", fmt.Errorf("error reading directory %s: %w", sourceDir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		sourcePath := filepath.Join(sourceDir, name)
+		targetPath := filepath.Join(targetDir, name)
+		if name == components[0] || utils.IsSymlinkCycle(sourcePath, wm.BaseDirPath) || isWithin(targetDir, sourcePath) || wm.isIgnored(name) {
+			continue
+		}
+
+		if wm.Mode == ModeCopy {
+			err = copyTree(sourcePath, targetPath, nil)
+		} else {
+			err = os.Symlink(linkTarget(targetDir, sourcePath), targetPath)
+		}
+		if err != nil {
+			return "", fmt.Errorf("error mirroring %s to %s: %w", sourcePath, targetPath, err)
+		}
+	}
+
+	nextTarget := filepath.Join(targetDir, components[0])
+	if err := os.MkdirAll(nextTarget, 0755); err != nil {
+		return "", fmt.Errorf("error creating directory %s: %w", nextTarget, err)
+	}
+	return wm.mirrorPath(filepath.Join(sourceDir, components[0]), nextTarget, components[1:])
+}
+
+// symlink creates symlinks for all files and directories in the module directory sourceDir
+func (wm *WorkspaceManager) symlink(sourceDir, targetDir string) error {
+	entries, err := os.ReadDir(sourceDir)
 	if err != nil {
 		return fmt.Errorf("error reading base directory: %w", err)
 	}
@@ -77,25 +295,36 @@ func (wm *WorkspaceManager) symlink(targetDir string) error {
 	for _, entry := range entries {
 		name := entry.Name()
 
-		sourcePath := filepath.Join(wm.BaseDirPath, name)
+		sourcePath := filepath.Join(sourceDir, name)
 		targetPath := filepath.Join(targetDir, name)
 
-		// Terraform.tfstate needs to be unique for every workspace
+		// Symlinks looping back into the module would make the workspace recursive
+		if utils.IsSymlinkCycle(sourcePath, sourceDir) {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: symlink cycle detected\n", sourcePath)
+			continue
+		}
+
+		if wm.isIgnored(name) {
+			continue
+		}
+
+		// Terraform.tfstate and the init marker need to be unique for every workspace
 		if name == ".terraform" {
 			if err := os.MkdirAll(targetPath, 0755); err != nil {
 				return fmt.Errorf("error creating .terraform directory: %w", err)
 			}
 			skipFunc := func(name string) bool {
-				return strings.Contains(name, "terraform.tfstate")
+				return strings.Contains(name, "terraform.tfstate") || name == InitMarkerFile
 			}
 			if err := wm.conditionalSymlink(sourcePath, targetPath, skipFunc); err != nil {
 				return fmt.Errorf("error creating symlinks in .terraform directory: %w", err)
 			}
-		} else {
-			relPath, err := filepath.Rel(targetDir, sourcePath)
-			if err != nil {
-				return fmt.Errorf("error calculating relative path from %s to %s: %w", targetDir, sourcePath, err)
+		} else if entry.IsDir() && wm.isIsolated(name) {
+			if err := wm.mirrorDir(sourcePath, targetPath); err != nil {
+				return fmt.Errorf("error mirroring directory %s: %w", name, err)
 			}
+		} else {
+			relPath := linkTarget(targetDir, sourcePath)
 			if err := os.Symlink(relPath, targetPath); err != nil {
 				return fmt.Errorf("error creating symlink from %s to %s: %w", relPath, targetPath, err)
 			}
@@ -105,6 +334,48 @@ func (wm *WorkspaceManager) symlink(targetDir string) error {
 	return nil
 }
 
+// isIsolated reports whether the module directory at relPath is in IsolatedDirs
+func (wm *WorkspaceManager) isIsolated(relPath string) bool {
+	for _, dir := range wm.IsolatedDirs {
+		if filepath.Clean(dir) == relPath {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorDir recreates the directory tree of sourceDir in targetDir with real directories and
+// per-file symlinks, so files written by terraform stay inside the workspace
+func (wm *WorkspaceManager) mirrorDir(sourceDir, targetDir string) error {
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fmt.Errorf("error creating directory %s: %w", targetDir, err)
+	}
+
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return fmt.Errorf("error reading directory %s: %w", sourceDir, err)
+	}
+
+	for _, entry := range entries {
+		sourcePath := filepath.Join(sourceDir, entry.Name())
+		targetPath := filepath.Join(targetDir, entry.Name())
+
+		if entry.IsDir() {
+			if err := wm.mirrorDir(sourcePath, targetPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		relPath := linkTarget(targetDir, sourcePath)
+		if err := os.Symlink(relPath, targetPath); err != nil {
+			return fmt.Errorf("error creating symlink from %s to %s: %w", relPath, targetPath, err)
+		}
+	}
+
+	return nil
+}
+
 func (wm *WorkspaceManager) conditionalSymlink(sourceDir, targetDir string, skipFunc func(string) bool) error {
 	entries, err := os.ReadDir(sourceDir)
 	if err != nil {
@@ -123,10 +394,7 @@ func (wm *WorkspaceManager) conditionalSymlink(sourceDir, targetDir string, skip
 		targetPath := filepath.Join(targetDir, name)
 
 		// Calculate relative path from target to source
-		relPath, err := filepath.Rel(targetDir, sourcePath)
-		if err != nil {
-			return fmt.Errorf("error calculating relative path from %s to %s: %w", targetDir, sourcePath, err)
-		}
+		relPath := linkTarget(targetDir, sourcePath)
 
 		// Create symlink for both files and directories
 		if err := os.Symlink(relPath, targetPath); err != nil {
@@ -137,10 +405,122 @@ func (wm *WorkspaceManager) conditionalSymlink(sourceDir, targetDir string, skip
 	return nil
 }
 
+// copyFiles copies all files and directories in the module directory sourceDir, mirroring symlink's .terraform handling
+func (wm *WorkspaceManager) copyFiles(sourceDir, targetDir string) error {
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return fmt.Errorf("error reading base directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		sourcePath := filepath.Join(sourceDir, name)
+		targetPath := filepath.Join(targetDir, name)
+
+		if utils.IsSymlinkCycle(sourcePath, sourceDir) {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: symlink cycle detected\n", sourcePath)
+			continue
+		}
+
+		// Workspaces placed inside the module must not be copied into themselves
+		if isWithin(targetDir, sourcePath) || wm.isIgnored(name) {
+			continue
+		}
+
+		var skipFunc func(string) bool
+		if name == ".terraform" {
+			skipFunc = func(name string) bool {
+				return strings.Contains(name, "terraform.tfstate") || name == InitMarkerFile
+			}
+		}
+		if err := copyTree(sourcePath, targetPath, skipFunc); err != nil {
+			return fmt.Errorf("error copying %s to %s: %w", sourcePath, targetPath, err)
+		}
+	}
+
+	return nil
+}
+
+// copyTree recursively copies sourcePath to targetPath, following symlinks.
+// Top-level entries of a directory for which skipFunc returns true are left out.
+func copyTree(sourcePath, targetPath string, skipFunc func(string) bool) error {
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return copyFile(sourcePath, targetPath, info.Mode().Perm())
+	}
+
+	if err := os.MkdirAll(targetPath, info.Mode().Perm()|0700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(sourcePath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if skipFunc != nil && skipFunc(entry.Name()) {
+			continue
+		}
+		if err := copyTree(filepath.Join(sourcePath, entry.Name()), filepath.Join(targetPath, entry.Name()), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies a single regular file, preserving its permissions
+func copyFile(sourcePath, targetPath string, perm os.FileMode) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// isWithin reports whether path is dir or lies inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// linkTarget returns the path a symlink in targetDir should use to reach sourcePath.
+// Relative paths are preferred; absolute paths are used when no relative path exists (e.g. across drives).
+// The kernel resolves a relative link from the real directory holding it, so both sides are resolved
+// first; sourcePath itself stays unresolved so links in the module keep pointing where they did.
+func linkTarget(targetDir, sourcePath string) string {
+	realTargetDir, err := filepath.EvalSymlinks(targetDir)
+	if err != nil {
+		return sourcePath
+	}
+	realSourceDir, err := filepath.EvalSymlinks(filepath.Dir(sourcePath))
+	if err != nil {
+		return sourcePath
+	}
+	relPath, err := filepath.Rel(realTargetDir, filepath.Join(realSourceDir, filepath.Base(sourcePath)))
+	if err != nil {
+		return sourcePath
+	}
+	return relPath
+}
+
 // Cleanup removes only the workspaces created by this operation
 func (wm *WorkspaceManager) Cleanup() error {
 	// Get the directory where workspaces were created
-	workspaceParent := filepath.Dir(wm.BaseDirPath)
+	workspaceParent := wm.WorkspaceParent
 	workspaceDir := filepath.Base(wm.BaseDirPath)
 
 	// Read the workspace parent directory to find directories with our pattern
@@ -162,13 +542,19 @@ func (wm *WorkspaceManager) Cleanup() error {
 			}
 		}
 	}
-	// Clear the ProfileSpaces map
+	// Clear the ProfileSpaces map; a per-run plugin cache matched the pattern and is gone too
+	wm.spacesMutex.Lock()
 	wm.ProfileSpaces = make(map[string]string)
+	wm.spacesMutex.Unlock()
+	wm.PluginCacheDir = ""
+	wm.PersistentCache = false
 	return nil
 }
 
 // GetWorkspacePath returns the workspace path for a given profile
 func (wm *WorkspaceManager) GetWorkspacePath(profileName string) (string, bool) {
+	wm.spacesMutex.Lock()
+	defer wm.spacesMutex.Unlock()
 	path, exists := wm.ProfileSpaces[profileName]
 	return path, exists
 }
@@ -0,0 +1,332 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateWorkspacesCopyMode(t *testing.T) {
+	parent := t.TempDir()
+	module := filepath.Join(parent, "module")
+	os.MkdirAll(filepath.Join(module, ".terraform", "providers"), 0755)
+	os.WriteFile(filepath.Join(module, "main.tf"), []byte("# main"), 0644)
+	os.WriteFile(filepath.Join(module, ".terraform", "terraform.tfstate"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(module, ".terraform", InitMarkerFile), []byte("marker"), 0644)
+	os.WriteFile(filepath.Join(module, ".terraform", "providers", "provider"), []byte("binary"), 0755)
+
+	wm := &WorkspaceManager{
+		BaseDirPath:     module,
+		WorkspaceParent: parent,
+		OperationID:     "test",
+		ProfileSpaces:   make(map[string]string),
+	}
+	if err := wm.SetMode(ModeCopy); err != nil {
+		t.Fatalf("Expected copy mode to be valid, got: %v", err)
+	}
+	if err := wm.CreateWorkspaces([]Profile{{Name: "dev"}}, 2); err != nil {
+		t.Fatalf("Expected no error creating workspaces, got: %v", err)
+	}
+
+	workspacePath, _ := wm.GetWorkspacePath("dev")
+	info, err := os.Lstat(filepath.Join(workspacePath, "main.tf"))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("Expected main.tf to be copied, got: %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(workspacePath, ".terraform", "providers", "provider")); string(data) != "binary" {
+		t.Errorf("Expected providers to be copied, got: %q", data)
+	}
+	for _, name := range []string{"terraform.tfstate", InitMarkerFile} {
+		if _, err := os.Stat(filepath.Join(workspacePath, ".terraform", name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be unique to the workspace, got: %v", name, err)
+		}
+	}
+
+	if err := wm.SetMode("hardlink"); err == nil {
+		t.Error("Expected an invalid mode to be rejected")
+	}
+}
+
+func TestCreateWorkspacesParallel(t *testing.T) {
+	parent := t.TempDir()
+	module := filepath.Join(parent, "module")
+	os.MkdirAll(module, 0755)
+	os.WriteFile(filepath.Join(module, "main.tf"), []byte("# main"), 0644)
+
+	wm := &WorkspaceManager{
+		BaseDirPath:     module,
+		WorkspaceParent: parent,
+		OperationID:     "test",
+		ProfileSpaces:   make(map[string]string),
+		Mode:            ModeSymlink,
+	}
+	profiles := []Profile{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	if err := wm.CreateWorkspaces(profiles, 2); err != nil {
+		t.Fatalf("Expected no error creating workspaces, got: %v", err)
+	}
+	for _, profile := range profiles {
+		workspacePath, exists := wm.GetWorkspacePath(profile.Name)
+		if !exists {
+			t.Fatalf("Expected a workspace for profile %s", profile.Name)
+		}
+		if _, err := os.Stat(filepath.Join(workspacePath, "main.tf")); err != nil {
+			t.Errorf("Expected main.tf in workspace of %s, got: %v", profile.Name, err)
+		}
+	}
+
+	// Every failing profile is reported, not just the first
+	blocker := filepath.Join(parent, "blocker")
+	os.WriteFile(blocker, nil, 0644)
+	wm.WorkspaceParent = blocker
+	err := wm.CreateWorkspaces([]Profile{{Name: "x"}, {Name: "y"}}, 2)
+	if err == nil || !strings.Contains(err.Error(), "-x-test") || !strings.Contains(err.Error(), "-y-test") {
+		t.Errorf("Expected errors for both profiles, got: %v", err)
+	}
+}
+
+func TestCreateWorkspacesIsolatedDirs(t *testing.T) {
+	parent := t.TempDir()
+	module := filepath.Join(parent, "module")
+	os.MkdirAll(filepath.Join(module, "modules", "network"), 0755)
+	os.MkdirAll(filepath.Join(module, "shared"), 0755)
+	os.WriteFile(filepath.Join(module, "modules", "network", "main.tf"), []byte("# network"), 0644)
+
+	wm := &WorkspaceManager{
+		BaseDirPath:     module,
+		WorkspaceParent: parent,
+		OperationID:     "test",
+		ProfileSpaces:   make(map[string]string),
+		Mode:            ModeSymlink,
+		IsolatedDirs:    []string{"modules"},
+	}
+	if err := wm.CreateWorkspaces([]Profile{{Name: "dev"}}, 1); err != nil {
+		t.Fatalf("Expected no error creating workspaces, got: %v", err)
+	}
+	workspacePath, _ := wm.GetWorkspacePath("dev")
+
+	for _, dir := range []string{"modules", filepath.Join("modules", "network")} {
+		info, err := os.Lstat(filepath.Join(workspacePath, dir))
+		if err != nil || !info.IsDir() {
+			t.Errorf("Expected %s to be a real directory, got: %v, %v", dir, info, err)
+		}
+	}
+	info, err := os.Lstat(filepath.Join(workspacePath, "modules", "network", "main.tf"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected main.tf to be a symlink, got: %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(workspacePath, "modules", "network", "main.tf")); string(data) != "# network" {
+		t.Errorf("Expected the symlink to resolve to the module file, got: %q", data)
+	}
+
+	// Directories that are not isolated are still linked whole
+	info, err = os.Lstat(filepath.Join(workspacePath, "shared"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected shared to be a symlink, got: %v, %v", info, err)
+	}
+}
+
+func TestCreatePluginCache(t *testing.T) {
+	t.Setenv(PluginCacheEnv, "")
+	t.Setenv("TF_PLUGIN_CACHE_DIR", "")
+	parent := t.TempDir()
+	module := filepath.Join(parent, "module")
+	os.MkdirAll(module, 0755)
+
+	wm := &WorkspaceManager{
+		BaseDirPath:     module,
+		WorkspaceParent: parent,
+		OperationID:     "test",
+		ProfileSpaces:   make(map[string]string),
+		Mode:            ModeSymlink,
+	}
+
+	// A per-run cache is removed together with the workspaces
+	cacheDir, err := wm.CreatePluginCache()
+	if err != nil {
+		t.Fatalf("CreatePluginCache failed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Fatalf("Expected the cache directory to exist, got: %v", err)
+	}
+	if wm.PersistentCache {
+		t.Error("Expected the per-run cache not to be reported as persistent")
+	}
+	if err := wm.Cleanup(); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Errorf("Expected the per-run cache to be removed, got: %v", err)
+	}
+
+	// A persistent cache survives the cleanup
+	persistent := filepath.Join(parent, "cache")
+	t.Setenv(PluginCacheEnv, persistent)
+	if cacheDir, err = wm.CreatePluginCache(); err != nil || cacheDir != persistent {
+		t.Fatalf("Expected the persistent cache %s, got %s (%v)", persistent, cacheDir, err)
+	}
+	if !wm.PersistentCache {
+		t.Error("Expected the cache to be reported as persistent")
+	}
+	wm.Cleanup()
+	if _, err := os.Stat(persistent); err != nil {
+		t.Errorf("Expected the persistent cache to be kept, got: %v", err)
+	}
+}
+
+func TestCreateWorkspacesNestedModule(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "repo")
+	os.MkdirAll(filepath.Join(root, "modules", "vpc"), 0755)
+	os.MkdirAll(filepath.Join(root, "networking", ".terraform"), 0755)
+	os.WriteFile(filepath.Join(root, "networking", "main.tf"), []byte(`module "vpc" { source = "../modules/vpc" }`), 0644)
+	os.WriteFile(filepath.Join(root, "networking", ".terraform", "terraform.tfstate"), []byte("{}"), 0644)
+
+	wm := &WorkspaceManager{
+		BaseDirPath:     root,
+		WorkspaceParent: parent,
+		OperationID:     "test",
+		ProfileSpaces:   make(map[string]string),
+		Mode:            ModeSymlink,
+	}
+	if err := wm.CreateWorkspaces([]Profile{{Name: "networking/prod", Dir: "networking"}}, 1); err != nil {
+		t.Fatalf("Expected no error creating workspaces, got: %v", err)
+	}
+
+	workspacePath, _ := wm.GetWorkspacePath("networking/prod")
+	if workspacePath != filepath.Join(parent, ".repo-networking%2Fprod-test", "networking") {
+		t.Fatalf("Expected the module directory inside a flat workspace, got: %s", workspacePath)
+	}
+	if _, err := os.Stat(filepath.Join(workspacePath, "main.tf")); err != nil {
+		t.Errorf("Expected the module's files in the workspace, got: %v", err)
+	}
+	// Relative module sources resolve against the mirrored tree
+	if _, err := os.Stat(filepath.Join(workspacePath, "..", "modules", "vpc")); err != nil {
+		t.Errorf("Expected ../modules to resolve inside the workspace, got: %v", err)
+	}
+	if info, err := os.Lstat(filepath.Join(workspacePath, ".terraform")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("Expected a workspace-specific .terraform directory, got: %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(workspacePath, ".terraform", "terraform.tfstate")); !os.IsNotExist(err) {
+		t.Errorf("Expected the module's backend state not to be shared, got: %v", err)
+	}
+
+	if err := wm.Cleanup(); err != nil {
+		t.Fatalf("Expected no error cleaning up, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, ".repo-networking%2Fprod-test")); !os.IsNotExist(err) {
+		t.Errorf("Expected the workspace to be removed, got: %v", err)
+	}
+}
+
+func TestCreateWorkspacesSymlinkedParent(t *testing.T) {
+	parent := t.TempDir()
+	module := filepath.Join(parent, "repo", "module")
+	os.MkdirAll(module, 0755)
+	os.WriteFile(filepath.Join(module, "main.tf"), []byte("# main"), 0644)
+
+	// The workspace parent is reached through a symlink at a different depth than its real location
+	realParent := filepath.Join(parent, "disk", "a", "b")
+	os.MkdirAll(realParent, 0755)
+	os.Symlink(realParent, filepath.Join(parent, "fast"))
+
+	wm := &WorkspaceManager{
+		BaseDirPath:   module,
+		OperationID:   "test",
+		ProfileSpaces: make(map[string]string),
+		Mode:          ModeSymlink,
+	}
+	if err := wm.SetWorkspaceParent(filepath.Join(parent, "fast")); err != nil {
+		t.Fatalf("Expected no error setting the workspace parent, got: %v", err)
+	}
+	if err := wm.CreateWorkspaces([]Profile{{Name: "dev"}}, 1); err != nil {
+		t.Fatalf("Expected no error creating workspaces, got: %v", err)
+	}
+	defer wm.Cleanup()
+
+	workspacePath, _ := wm.GetWorkspacePath("dev")
+	if content, err := os.ReadFile(filepath.Join(workspacePath, "main.tf")); err != nil || string(content) != "# main" {
+		t.Errorf("Expected the module's files to resolve through the symlinked parent, got: %q, %v", content, err)
+	}
+}
+
+func TestNewWorkspaceManagerBaseDir(t *testing.T) {
+	parent := t.TempDir()
+	module := filepath.Join(parent, "module")
+	os.MkdirAll(module, 0755)
+
+	wm, err := NewWorkspaceManager(module)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if wm.BaseDirPath != module || wm.WorkspaceParent != parent {
+		t.Errorf("Expected workspaces of %s next to it, got base %s and parent %s", module, wm.BaseDirPath, wm.WorkspaceParent)
+	}
+}
+
+func TestCreateWorkspacesIgnorePatterns(t *testing.T) {
+	parent := t.TempDir()
+	module := filepath.Join(parent, "module")
+	os.MkdirAll(filepath.Join(module, ".git"), 0755)
+	os.WriteFile(filepath.Join(module, "main.tf"), []byte("# main"), 0644)
+	os.WriteFile(filepath.Join(module, "terraform.tfstate.backup"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(module, "main.tf~"), []byte("# old"), 0644)
+	os.WriteFile(filepath.Join(module, "notes.md"), []byte("notes"), 0644)
+
+	wm := &WorkspaceManager{
+		BaseDirPath:     module,
+		WorkspaceParent: parent,
+		OperationID:     "test",
+		ProfileSpaces:   make(map[string]string),
+		IgnorePatterns:  DefaultIgnorePatterns,
+	}
+	if err := wm.AddIgnorePatterns([]string{"*.md"}); err != nil {
+		t.Fatalf("Expected valid pattern, got: %v", err)
+	}
+	if err := wm.AddIgnorePatterns([]string{"docs/*.md"}); err == nil {
+		t.Error("Expected an error for a nested pattern")
+	}
+	if err := wm.CreateWorkspaces([]Profile{{Name: "dev"}}, 1); err != nil {
+		t.Fatalf("Expected no error creating workspaces, got: %v", err)
+	}
+
+	workspacePath, _ := wm.GetWorkspacePath("dev")
+	if _, err := os.Lstat(filepath.Join(workspacePath, "main.tf")); err != nil {
+		t.Errorf("Expected main.tf in the workspace, got: %v", err)
+	}
+	for _, name := range []string{".git", "terraform.tfstate.backup", "main.tf~", "notes.md"} {
+		if _, err := os.Lstat(filepath.Join(workspacePath, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be left out of the workspace, got: %v", name, err)
+		}
+	}
+}
+
+func TestCreateWorkspacesSkipsLeftoverWorkspaces(t *testing.T) {
+	module := filepath.Join(t.TempDir(), "module")
+	os.MkdirAll(module, 0755)
+	os.WriteFile(filepath.Join(module, "main.tf"), []byte("# main"), 0644)
+	os.WriteFile(filepath.Join(module, ".module-notes"), []byte("kept"), 0644)
+
+	// Workspaces inside the module, kept after each run
+	for _, operationID := range []string{"0badc0de", "deadbeef"} {
+		wm := &WorkspaceManager{
+			BaseDirPath:     module,
+			WorkspaceParent: module,
+			OperationID:     operationID,
+			ProfileSpaces:   make(map[string]string),
+		}
+		if err := wm.CreateWorkspaces([]Profile{{Name: "dev"}}, 1); err != nil {
+			t.Fatalf("Expected no error creating workspaces, got: %v", err)
+		}
+
+		workspacePath, _ := wm.GetWorkspacePath("dev")
+		entries, _ := os.ReadDir(workspacePath)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		if strings.Join(names, ",") != ".module-notes,main.tf" {
+			t.Errorf("Expected no nested workspaces in %s, got: %v", operationID, names)
+		}
+	}
+}